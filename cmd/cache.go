@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local API response cache",
+	Long: `Search and query responses are cached on disk and reused until they are older
+than cache_ttl (default 24h, 0 for never, change it with rapiddns config set cache_ttl). Use --no-cache to
+bypass the cache for a single run or --refresh to force fresh results.`,
+	Args: noSubcommand,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache location, size and number of entries",
+	Args:  exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rc, err := newCache()
+		if err != nil {
+			return fmt.Errorf("opening cache: %w", err)
+		}
+		st, err := rc.Stats()
+		if err != nil {
+			return fmt.Errorf("reading cache: %w", err)
+		}
+
+		fmt.Printf("Directory:  %s\n", rc.Dir)
+		fmt.Printf("TTL:        %s\n", rc.TTL)
+		fmt.Printf("Entries:    %d (%d expired)\n", st.Entries, st.Expired)
+		fmt.Printf("Size:       %.1f KiB\n", float64(st.Bytes)/1024)
+		if !st.Oldest.IsZero() {
+			fmt.Printf("Oldest:     %s\n", st.Oldest.Format("2006-01-02 15:04:05"))
+			fmt.Printf("Newest:     %s\n", st.Newest.Format("2006-01-02 15:04:05"))
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rc, err := newCache()
+		if err != nil {
+			return fmt.Errorf("opening cache: %w", err)
+		}
+		n, err := rc.Clear()
+		if err != nil {
+			return fmt.Errorf("clearing cache: %w", err)
+		}
+		slog.Info("removed cached responses", "count", n)
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired cached responses",
+	Args:  exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rc, err := newCache()
+		if err != nil {
+			return fmt.Errorf("opening cache: %w", err)
+		}
+		n, err := rc.Prune()
+		if err != nil {
+			return fmt.Errorf("pruning cache: %w", err)
+		}
+		slog.Info("removed expired responses", "count", n)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+}
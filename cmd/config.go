@@ -2,49 +2,203 @@ package cmd
 
 import (
 	"fmt"
-	"rapiddns-cli/internal/config"
+	"log/slog"
+	"strings"
 
+	"github.com/rapiddns/rapiddns-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure RapidDNS CLI settings",
+	Args:  noSubcommand,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
 }
 
+var setKeyEncrypt bool
+
 var setKeyCmd = &cobra.Command{
 	Use:   "set-key [key]",
 	Short: "Set the API key",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		key := args[0]
-		err := config.SetAPIKey(key)
+	Long: `Set the API key in the config file, in the active profile if there is one.
+If the key is omitted it is read from the terminal without echo.
+
+With --encrypt the key is stored encrypted with a passphrase, asked for on the
+terminal or taken from RAPIDDNS_PASSPHRASE. The same passphrase unlocks the key
+whenever it is needed.`,
+	Args: maximumArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var key string
+		if len(args) == 1 {
+			key = args[0]
+		} else {
+			var err error
+			if key, err = config.ReadSecret("API key: "); err != nil {
+				return usageErrorf("reading API key (or pass it as an argument): %v", err)
+			}
+		}
+		if key == "" {
+			return usageErrorf("API key must not be empty")
+		}
+
+		if !setKeyEncrypt {
+			if err := config.SetAPIKey(key); err != nil {
+				return fmt.Errorf("setting API key: %w", err)
+			}
+			slog.Info("API key set", profileAttr())
+			return nil
+		}
+
+		passphrase, err := config.Passphrase(true)
 		if err != nil {
-			fmt.Printf("Error setting API key: %v\n", err)
-			return
+			return err
+		}
+		if err := config.SetEncryptedAPIKey(key, passphrase); err != nil {
+			return fmt.Errorf("setting API key: %w", err)
 		}
-		fmt.Println("API key set successfully.")
+		slog.Info("encrypted API key set", profileAttr())
+		return nil
 	},
 }
 
 var getKeyCmd = &cobra.Command{
 	Use:   "get-key",
-	Short: "Get the current API key",
-	Run: func(cmd *cobra.Command, args []string) {
-		key := config.GetAPIKey()
+	Short: "Show the current API key, masked",
+	Args:  exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := config.GetAPIKey()
+		if err != nil {
+			return err
+		}
 		if key == "" {
-			fmt.Println("API key is not set.")
+			slog.Warn("API key is not set")
+		} else {
+			fmt.Printf("Current API key: %s\n", maskSecret(key))
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Change a setting in the config file",
+	Long: `Change a setting in the config file, in the active profile if there is one.
+Values are validated against the type of the setting. Run 'rapiddns config list'
+to see all settings.`,
+	Args: exactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := lookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		value, err := s.Parse(args[1])
+		if err != nil {
+			return usageError{err}
+		}
+		if err := config.Set(s.Key, value); err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+		slog.Info("setting changed", "key", s.Key, profileAttr())
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show the effective value of a setting",
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := lookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		value, set := s.Value()
+		if set && s.Secret {
+			value = maskSecret(value)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their effective values",
+	Args:  exactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, s := range config.Settings {
+			value, set := s.Value()
+			if set && s.Secret {
+				value = maskSecret(value)
+			}
+			switch {
+			case !set && value == "":
+				value = "(not set)"
+			case !set:
+				value += " (default)"
+			}
+			fmt.Printf("%-22s %-10s %s\n", s.Key, s.Kind, value)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset [key]",
+	Short: "Remove a setting from the config file, restoring its default",
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := lookupSetting(args[0])
+		if err != nil {
+			return err
+		}
+		removed, err := config.Unset(s.Key)
+		if err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+		if removed {
+			slog.Info("setting removed", "key", s.Key, profileAttr())
 		} else {
-			fmt.Printf("Current API key: %s\n", key)
+			slog.Warn("setting was not set", "key", s.Key, profileAttr())
 		}
+		return nil
 	},
 }
 
+// profileAttr returns the active profile as a log attribute, or an empty attribute
+func profileAttr() slog.Attr {
+	if profile := config.ActiveProfile(); profile != "" {
+		return slog.String("profile", profile)
+	}
+	return slog.Attr{}
+}
+
+// lookupSetting returns the setting named key or a usage error
+func lookupSetting(key string) (config.Setting, error) {
+	s, ok := config.LookupSetting(key)
+	if !ok {
+		return s, usageErrorf("unknown setting %q, see: rapiddns config list", key)
+	}
+	return s, nil
+}
+
+// maskSecret hides all but the last four characters of a secret, and its length
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return "********" + secret[len(secret)-4:]
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(setKeyCmd)
+	setKeyCmd.Flags().BoolVar(&setKeyEncrypt, "encrypt", false, "Store the key encrypted with a passphrase")
 	configCmd.AddCommand(getKeyCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configUnsetCmd)
 }
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorTimeout bounds each network check unless --timeout is given
+	doctorTimeout = 15 * time.Second
+	// maxClockSkew is the clock difference to the API server reported as a problem
+	maxClockSkew = time.Minute
+	// doctorKeyword is searched for to validate the API key with a single record
+	doctorKeyword = "rapiddns.io"
+)
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration and the connection to the API",
+	Long: `Reports which config files, environment variables and profile are in effect,
+validates the API key with a minimal request, and checks the endpoint, proxy and TLS
+settings, the result directory and the system clock. Every problem comes with a fix.
+Exits with a non-zero code if any check fails.`,
+	Args: exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := &doctor{ctx: cmd.Context(), w: os.Stdout}
+
+		d.checkConfig()
+		apiKey := d.checkAPIKeySetting()
+		tlsConfig := d.checkTLS()
+		d.checkResultDir()
+		if offlineMode {
+			d.report(checkWarn, "network", "skipped in offline mode", "")
+		} else {
+			if d.checkEndpoint(tlsConfig) {
+				d.checkAPIKey(apiKey)
+			}
+		}
+
+		fmt.Fprintf(d.w, "\n%d failed, %d warnings\n", d.failed, d.warned)
+		if d.failed > 0 {
+			return fmt.Errorf("%d checks failed", d.failed)
+		}
+		return nil
+	},
+}
+
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+func (s checkStatus) String() string {
+	switch s {
+	case checkWarn:
+		return "WARN"
+	case checkFail:
+		return "FAIL"
+	}
+	return " OK "
+}
+
+// doctor runs the checks of config doctor and counts their outcomes
+type doctor struct {
+	ctx            context.Context
+	w              io.Writer
+	failed, warned int
+}
+
+// report prints the outcome of a check and, for problems, how to fix it
+func (d *doctor) report(status checkStatus, name, detail, fix string) {
+	fmt.Fprintf(d.w, "[%s] %-12s %s\n", status, name, detail)
+	if fix != "" && status != checkOK {
+		fmt.Fprintf(d.w, "       %-12s fix: %s\n", "", fix)
+	}
+	switch status {
+	case checkWarn:
+		d.warned++
+	case checkFail:
+		d.failed++
+	}
+}
+
+// checkConfig reports the config files, profile and environment in effect
+func (d *doctor) checkConfig() {
+	path, err := config.FilePath()
+	switch {
+	case err != nil:
+		d.report(checkFail, "config file", err.Error(), "pass an existing file with --config or RAPIDDNS_CONFIG")
+	case !fileExists(path):
+		d.report(checkOK, "config file", path+" (not created yet)", "")
+	default:
+		if mode, ok := config.ReadableByOthers(path); ok {
+			d.report(checkWarn, "config file", fmt.Sprintf("%s is readable by other users (mode %04o)", path, mode), "chmod 600 "+path)
+		} else {
+			d.report(checkOK, "config file", path, "")
+		}
+	}
+
+	if local := config.LocalFilePath(); local != "" {
+		d.report(checkOK, "project file", local+" (overrides the config file)", "")
+	}
+
+	if err := config.Err(); err != nil {
+		d.report(checkFail, "config", err.Error(), "check --config, --profile, RAPIDDNS_CONFIG and RAPIDDNS_PROFILE; 'rapiddns config profile list' shows the profiles")
+	} else if profile := config.ActiveProfile(); profile != "" {
+		d.report(checkOK, "profile", profile, "")
+	} else {
+		d.report(checkOK, "profile", "none, top level settings", "")
+	}
+
+	var names []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, config.EnvPrefix+"_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		d.report(checkOK, "environment", strings.Join(names, ", "), "")
+	} else {
+		d.report(checkOK, "environment", "no "+config.EnvPrefix+"_ variables set", "")
+	}
+}
+
+// checkAPIKeySetting reports where the API key comes from and resolves it
+func (d *doctor) checkAPIKeySetting() string {
+	source, env := config.APIKeySource()
+	if source == "" {
+		d.report(checkWarn, "api key", "not set, search results are limited and export is disabled",
+			"rapiddns config set-key <YOUR_API_KEY>")
+		return ""
+	}
+
+	key, err := config.GetAPIKey()
+	if err != nil {
+		var fix string
+		switch source {
+		case config.APIKeyEncrypted:
+			fix = "check the passphrase, or set the key again with 'rapiddns config set-key --encrypt'"
+		case config.APIKeyCommand:
+			fix = "run the api_key_command yourself to see why it fails"
+		}
+		d.report(checkFail, "api key", err.Error(), fix)
+		return ""
+	}
+	d.report(checkOK, "api key", fmt.Sprintf("%s from %s", maskSecret(key), keySourceName(source, env)), "")
+	return key
+}
+
+// keySourceName describes where the API key is read from: source is the setting
+// and env the environment variable it is set in, if any
+func keySourceName(source, env string) string {
+	where := "the config file"
+	if env != "" {
+		where = "the " + env + " environment variable"
+	}
+	switch source {
+	case config.APIKeyEncrypted:
+		return source + " in " + where + ", decrypted"
+	case config.APIKeyCommand:
+		return "the output of " + source + " in " + where
+	}
+	if env != "" {
+		return where
+	}
+	return source + " in " + where + ", in plain text"
+}
+
+// checkTLS reports the proxy and TLS settings and returns the TLS configuration
+// used by the endpoint check
+func (d *doctor) checkTLS() *tls.Config {
+	if proxy := config.GetProxy(); proxy != "" {
+		if proxyURL, err := rapiddns.ParseProxyURL(proxy); err != nil {
+			d.report(checkFail, "proxy", err.Error(), "rapiddns config set proxy http://host:port")
+		} else {
+			d.report(checkOK, "proxy", proxyURL.Redacted(), "")
+		}
+	} else if name, value := proxyFromEnvironment(); name != "" {
+		d.report(checkOK, "proxy", fmt.Sprintf("%s from %s", value, name), "")
+	} else {
+		d.report(checkOK, "proxy", "none", "")
+	}
+
+	caCert, clientCert, clientKey := config.GetTLSFiles()
+	tlsConfig, err := rapiddns.LoadTLSConfig(rapiddns.TLSOptions{
+		CACert:             caCert,
+		ClientCert:         clientCert,
+		ClientKey:          clientKey,
+		InsecureSkipVerify: config.GetInsecureTLS(),
+	})
+	switch {
+	case err != nil:
+		d.report(checkFail, "tls", err.Error(), "check ca_cert, client_cert and client_key with 'rapiddns config list'")
+		return nil
+	case config.GetInsecureTLS():
+		d.report(checkWarn, "tls", "certificate verification is disabled",
+			"set ca_cert to the CA bundle of your proxy and run 'rapiddns config unset insecure_skip_verify'")
+	case caCert != "":
+		d.report(checkOK, "tls", "system roots and "+caCert, "")
+	default:
+		d.report(checkOK, "tls", "system roots", "")
+	}
+	return tlsConfig
+}
+
+// proxyFromEnvironment returns the variable and the value, without password, of
+// the proxy set by the standard environment variables
+func proxyFromEnvironment() (name, value string) {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if v := os.Getenv(name); v != "" {
+			if u, err := url.Parse(v); err == nil {
+				v = u.Redacted()
+			}
+			return name, v
+		}
+	}
+	return "", ""
+}
+
+// checkResultDir reports whether result files can be written
+func (d *doctor) checkResultDir() {
+	dir := config.GetResultDir()
+	if err := checkWritable(dir); err != nil {
+		d.report(checkFail, "result dir", fmt.Sprintf("%s is not writable: %v", dir, err),
+			"rapiddns config set result_dir <WRITABLE_DIR>")
+		return
+	}
+	d.report(checkOK, "result dir", dir, "")
+}
+
+// checkWritable reports whether files can be created in dir, or in the closest
+// existing parent if dir does not exist yet
+func checkWritable(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if parent := filepath.Dir(abs); parent != abs {
+			return checkWritable(parent)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory")
+	}
+
+	f, err := os.CreateTemp(dir, ".rapiddns-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkEndpoint sends a plain request to the API endpoint through the configured
+// proxy and TLS settings, and compares the server's clock with the local one.
+// It reports whether the endpoint answered.
+func (d *doctor) checkEndpoint(tlsConfig *tls.Config) bool {
+	if tlsConfig == nil {
+		d.report(checkFail, "endpoint", "skipped, the TLS settings are invalid", "")
+		return false
+	}
+	endpoint := config.GetBaseURL()
+	if endpoint == "" {
+		endpoint = rapiddns.BaseURL
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if proxy := config.GetProxy(); proxy != "" {
+		proxyURL, err := rapiddns.ParseProxyURL(proxy)
+		if err != nil {
+			d.report(checkFail, "endpoint", "skipped, the proxy is invalid", "")
+			return false
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	client := &http.Client{Transport: transport, Timeout: probeTimeout()}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		d.report(checkFail, "endpoint", err.Error(), "rapiddns config set base_url https://rapiddns.io/api")
+		return false
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		d.report(checkFail, "endpoint", fmt.Sprintf("%s is not reachable: %v", endpoint, err), endpointFix(err))
+		return false
+	}
+	resp.Body.Close()
+	d.report(checkOK, "endpoint", fmt.Sprintf("%s answered HTTP %d in %s", endpoint, resp.StatusCode, latency.Round(time.Millisecond)), "")
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		d.report(checkWarn, "clock", "the server sent no Date header to compare with", "")
+		return true
+	}
+	// The Date header is truncated to seconds and sent half way through the request
+	skew := time.Until(serverTime.Add(latency / 2)).Round(time.Second)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		d.report(checkWarn, "clock", fmt.Sprintf("local clock differs from the server by %s", skew),
+			"synchronize the system clock, e.g. enable NTP")
+		return true
+	}
+	d.report(checkOK, "clock", fmt.Sprintf("within %s of the server", maxClockSkew), "")
+	return true
+}
+
+// endpointFix suggests how to fix a failed connection to the API
+func endpointFix(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var certInvalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "a TLS inspecting proxy is likely in the way, set ca_cert to its CA bundle: rapiddns config set ca_cert <PEM_FILE>"
+	case errors.As(err, &certInvalid):
+		return "check that the system clock is correct and the certificate has not expired"
+	case errors.As(err, &hostname):
+		return "check base_url, the certificate does not match the host name"
+	case isNetworkError(err):
+		return "check your network connection, firewall and proxy settings (rapiddns config set proxy <URL>)"
+	}
+	return "check base_url and proxy with 'rapiddns config list'"
+}
+
+// checkAPIKey validates the API key with a single record search
+func (d *doctor) checkAPIKey(apiKey string) {
+	if apiKey == "" {
+		return
+	}
+	client, err := newClientWithKey(apiKey, probeOptions()...)
+	if err != nil {
+		d.report(checkFail, "api access", err.Error(), "")
+		return
+	}
+
+	_, _, err = client.Search(d.ctx, doctorKeyword, 1, 1, "")
+	switch {
+	case err == nil:
+		d.report(checkOK, "api access", "the API key was accepted", "")
+	case errors.Is(err, rapiddns.ErrUnauthorized):
+		d.report(checkFail, "api access", "the API key was rejected: "+err.Error(),
+			"copy the key from https://rapiddns.io/user/profile and run 'rapiddns config set-key', or check that your plan is active")
+	case errors.Is(err, rapiddns.ErrQuotaExceeded), errors.Is(err, rapiddns.ErrRateLimited):
+		d.report(checkWarn, "api access", "the API key is valid but rate limited: "+err.Error(), "see 'rapiddns quota' or try again later")
+	default:
+		d.report(checkFail, "api access", err.Error(), errorHint(err))
+	}
+}
+
+// probeTimeout returns the timeout of a request checking the configuration
+func probeTimeout() time.Duration {
+	if requestTimeout == 0 {
+		return doctorTimeout
+	}
+	return requestTimeout
+}
+
+// probeOptions configure the client of a request checking the API key. It is
+// sent once and always to the API, a cached answer proves nothing.
+func probeOptions() []rapiddns.Option {
+	policy := rapiddns.DefaultRetryPolicy
+	policy.MaxAttempts = 1
+	return []rapiddns.Option{
+		rapiddns.WithCache(nil, false),
+		rapiddns.WithRetryPolicy(policy),
+		rapiddns.WithTimeout(probeTimeout()),
+	}
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func init() {
+	configCmd.AddCommand(configDoctorCmd)
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes, documented in the README. Codes are never reused for a
+// different meaning, scripts depend on them.
+const (
+	exitError        = 1   // Any failure not covered below
+	exitInvalidInput = 2   // Unknown command, invalid flags, arguments, profile or setting value, or the API rejected the query
+	exitUnauthorized = 3   // Missing, invalid or expired API key
+	exitRateLimited  = 4   // Rate limited by the API or local daily limit reached
+	exitServerError  = 5   // The API failed with a 5xx error
+	exitNetwork      = 6   // The API could not be reached or did not answer in time
+	exitPartial      = 7   // Some pages failed; the results written are incomplete
+	exitExportFailed = 8   // The server reported the export task as failed
+	exitNoResults    = 9   // The search or query succeeded but matched nothing
+	exitOffline      = 10  // Offline and the result is not cached or needs the network
+	exitInterrupted  = 130 // Interrupted with Ctrl-C
+)
+
+var (
+	// errAPIKeyRequired is returned by commands that cannot run without an API key
+	errAPIKeyRequired = errors.New("API key is required")
+	// errInvalidUsage wraps errors in flags and arguments
+	errInvalidUsage = errors.New("invalid usage")
+	// errPartialResults is returned after writing results some pages are missing from
+	errPartialResults = errors.New("results are incomplete")
+	// errNoResults is returned after writing an empty result
+	errNoResults = errors.New("no results found")
+)
+
+// usageError marks err as a mistake in flags or arguments without changing its message
+type usageError struct{ err error }
+
+func (e usageError) Error() string        { return e.err.Error() }
+func (e usageError) Unwrap() error        { return e.err }
+func (e usageError) Is(target error) bool { return target == errInvalidUsage }
+
+// usageErrorf formats an error matching errInvalidUsage
+func usageErrorf(format string, a ...interface{}) error {
+	return usageError{fmt.Errorf(format, a...)}
+}
+
+// exactArgs is cobra.ExactArgs reporting errInvalidUsage
+func exactArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(n)(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// maximumArgs is cobra.MaximumNArgs reporting errInvalidUsage
+func maximumArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(n)(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// noSubcommand rejects the arguments of a command that only groups subcommands.
+// Cobra would show the help of such a command, or fail without errInvalidUsage.
+func noSubcommand(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+	if cmd.SuggestionsMinimumDistance <= 0 {
+		cmd.SuggestionsMinimumDistance = 2 // cobra's default
+	}
+	if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		msg += "\n\nDid you mean this?\n\t" + strings.Join(suggestions, "\n\t")
+	}
+	return usageError{errors.New(msg)}
+}
+
+// isResultErr reports whether err still comes with results that should be written
+func isResultErr(err error) bool {
+	return errors.Is(err, errPartialResults) || errors.Is(err, errNoResults)
+}
+
+// exitCode maps an error returned by a command onto a process exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, errPartialResults):
+		return exitPartial
+	case errors.Is(err, errNoResults):
+		return exitNoResults
+	case errors.Is(err, rapiddns.ErrUnauthorized), errors.Is(err, errAPIKeyRequired):
+		return exitUnauthorized
+	case errors.Is(err, rapiddns.ErrRateLimited), errors.Is(err, rapiddns.ErrQuotaExceeded):
+		return exitRateLimited
+	case errors.Is(err, rapiddns.ErrInvalidQuery), errors.Is(err, errInvalidUsage):
+		return exitInvalidInput
+	case errors.Is(err, rapiddns.ErrServer):
+		return exitServerError
+	case errors.Is(err, rapiddns.ErrExportFailed):
+		return exitExportFailed
+	case errors.Is(err, rapiddns.ErrOffline):
+		return exitOffline
+	case isNetworkError(err):
+		return exitNetwork
+	}
+	return exitError
+}
+
+// isNetworkError reports whether err means the API could not be reached
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// errorHint returns an actionable suggestion for err, or an empty string
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, rapiddns.ErrUnauthorized), errors.Is(err, errAPIKeyRequired):
+		return "If you are not a PRO or MAX member, please purchase a plan at: https://rapiddns.io/pricing\n" +
+			"Then configure your API key using: rapiddns config set-key <YOUR_API_KEY>"
+	case errors.Is(err, rapiddns.ErrNotCached):
+		return "Run the same command online first to populate the cache, or point cache_dir at a copied cache"
+	case errors.Is(err, rapiddns.ErrOffline):
+		return "This command needs the network, run it without --offline"
+	case errors.Is(err, rapiddns.ErrQuotaExceeded):
+		return "Raise requests_per_day with rapiddns config set or try again tomorrow. See: rapiddns quota"
+	}
+	return ""
+}
+
+// errorReport is the JSON document written to stderr with --error-format json
+type errorReport struct {
+	Error      string `json:"error"`
+	Kind       string `json:"kind"`
+	ExitCode   int    `json:"exit_code"`
+	Hint       string `json:"hint,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	APIStatus  string `json:"api_status,omitempty"`
+	APIMessage string `json:"api_message,omitempty"`
+}
+
+// errorKind names the class of err for machine readable error reports
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "interrupted"
+	case errors.Is(err, errPartialResults):
+		return "partial_results"
+	case errors.Is(err, errNoResults):
+		return "no_results"
+	case errors.Is(err, rapiddns.ErrUnauthorized), errors.Is(err, errAPIKeyRequired):
+		return "unauthorized"
+	case errors.Is(err, rapiddns.ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, rapiddns.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, rapiddns.ErrInvalidQuery), errors.Is(err, errInvalidUsage):
+		return "invalid_input"
+	case errors.Is(err, rapiddns.ErrServer):
+		return "server_error"
+	case errors.Is(err, rapiddns.ErrNotCached):
+		return "not_cached"
+	case errors.Is(err, rapiddns.ErrOffline):
+		return "offline"
+	case errors.Is(err, rapiddns.ErrExportFailed):
+		return "export_failed"
+	case errors.Is(err, rapiddns.ErrUnexpectedResponse):
+		return "unexpected_response"
+	case isNetworkError(err):
+		return "network"
+	}
+	return "error"
+}
+
+// reportError writes err to stderr in the format selected with --error-format
+func reportError(err error) {
+	hint := errorHint(err)
+	if errorFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		return
+	}
+
+	report := errorReport{
+		Error:    err.Error(),
+		Kind:     errorKind(err),
+		ExitCode: exitCode(err),
+		Hint:     hint,
+	}
+	var apiErr *rapiddns.APIError
+	if errors.As(err, &apiErr) {
+		report.HTTPStatus = apiErr.HTTPStatus
+		report.APIStatus = apiErr.Status
+		report.APIMessage = apiErr.Msg
+	}
+	encoder := json.NewEncoder(os.Stderr)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(report)
+}
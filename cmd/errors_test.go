@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+)
+
+func TestExitCodeAndKind(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		code int
+		kind string
+	}{
+		{"other", errors.New("disk full"), exitError, "error"},
+		{"interrupted", fmt.Errorf("search: %w", context.Canceled), exitInterrupted, "interrupted"},
+		{"interrupted with partial results", fmt.Errorf("%w: interrupted: %w", errPartialResults, context.Canceled), exitInterrupted, "interrupted"},
+		{"partial results", fmt.Errorf("%w: 2 page(s) failed", errPartialResults), exitPartial, "partial_results"},
+		{"no results", errNoResults, exitNoResults, "no_results"},
+		{"no API key", errAPIKeyRequired, exitUnauthorized, "unauthorized"},
+		{"rejected API key", &rapiddns.APIError{HTTPStatus: 401}, exitUnauthorized, "unauthorized"},
+		{"rate limited", &rapiddns.APIError{HTTPStatus: 429}, exitRateLimited, "rate_limited"},
+		{"daily limit", rapiddns.ErrQuotaExceeded, exitRateLimited, "quota_exceeded"},
+		{"invalid query", &rapiddns.APIError{HTTPStatus: 200, Status: "400"}, exitInvalidInput, "invalid_input"},
+		{"invalid flag", usageErrorf("invalid --error-format %q", "xml"), exitInvalidInput, "invalid_input"},
+		{"unknown profile", usageError{fmt.Errorf("%w %q", config.ErrUnknownProfile, "nope")}, exitInvalidInput, "invalid_input"},
+		{"server error", &rapiddns.PageError{Page: 3, Err: &rapiddns.APIError{HTTPStatus: 502}}, exitServerError, "server_error"},
+		{"export failed", rapiddns.ErrExportFailed, exitExportFailed, "export_failed"},
+		{"not cached", fmt.Errorf("%w for search %q", rapiddns.ErrNotCached, "example.com"), exitOffline, "not_cached"},
+		{"offline", rapiddns.ErrOffline, exitOffline, "offline"},
+		{"unexpected response", rapiddns.ErrUnexpectedResponse, exitError, "unexpected_response"},
+		{"unreachable", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, exitNetwork, "network"},
+		{"timeout", fmt.Errorf("search: %w", context.DeadlineExceeded), exitNetwork, "network"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.code {
+				t.Errorf("exitCode() = %d, want %d", got, tt.code)
+			}
+			if got := errorKind(tt.err); got != tt.kind {
+				t.Errorf("errorKind() = %q, want %q", got, tt.kind)
+			}
+		})
+	}
+}
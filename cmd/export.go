@@ -3,16 +3,17 @@ package cmd
 import (
 	"archive/zip"
 	"encoding/csv"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"rapiddns-cli/internal/api"
-	"rapiddns-cli/internal/config"
 	"strings"
 	"time"
 
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
 	"github.com/spf13/cobra"
 )
 
@@ -27,71 +28,64 @@ var (
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export data operations",
+	Args:  noSubcommand,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
 }
 
 var exportStartCmd = &cobra.Command{
 	Use:   "start [query_input]",
 	Short: "Start a data export task, wait for completion, and download result",
-	Long: `Starts a data export task, polls the status until completion, and downloads the result to the local 'result' directory.
+	Long: `Starts a data export task, polls the status until completion, and downloads the result to the result directory (result_dir, default 'result').
 Default compression is enabled (ZIP). If compressed, it will also extract the file.
 Can optionally extract subdomains and IPs from the downloaded result (CSV only).`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if config.GetAPIKey() == "" {
-			fmt.Println("Error: API key is required for export operations.")
-			fmt.Println("If you are not a PRO or MAX member, please purchase a plan at: https://rapiddns.io/pricing")
-			fmt.Println("Then configure your API key using: rapiddns config set-key <YOUR_API_KEY>")
-			return
+	Args: exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := config.GetAPIKey()
+		if err != nil {
+			return err
+		}
+		if apiKey == "" {
+			return fmt.Errorf("%w for export operations", errAPIKeyRequired)
 		}
 		queryInput := args[0]
-		client := api.NewClient()
+		ctx := cmd.Context()
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
 
-		fmt.Printf("Starting export task for '%s' (Type: %s, Max: %d)...\n", queryInput, exportType, exportMaxResults)
+		slog.Info("starting export task", "query", queryInput, "type", exportType, "max", exportMaxResults)
 
 		// 1. Start Export Task
-		data, err := client.ExportData(exportType, queryInput, exportMaxResults, exportCompress)
+		data, err := client.ExportData(ctx, exportType, queryInput, exportMaxResults, exportCompress)
 		if err != nil {
-			fmt.Printf("Error starting export: %v\n", err)
-			return
+			return fmt.Errorf("starting export: %w", err)
 		}
 
 		taskID := data.ExportID
-		fmt.Printf("Export task started. Task ID: %s\n", taskID)
+		slog.Info("export task started", "task_id", taskID)
 
 		// 2. Poll Status
-		fmt.Println("Waiting for task completion...")
-		var downloadURL string
-		for {
-			statusData, err := client.CheckExportStatus(taskID)
-			if err != nil {
-				fmt.Printf("Error checking status: %v. Retrying in 5 seconds...\n", err)
-				time.Sleep(5 * time.Second)
-				continue
+		final, err := client.WaitExport(ctx, taskID, 2*time.Second, func(status *rapiddns.ExportStatusData) {
+			slog.Info("export task status", "task_id", taskID, "status", status.Status, "progress", status.ProgressPercent)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("interrupted, export task %s keeps running on the server, check it later with: rapiddns export status %s: %w", taskID, taskID, err)
 			}
-
-			fmt.Printf("Status: %s (Progress: %d%%)\n", statusData.Status, statusData.ProgressPercent)
-
-			if statusData.Status == "completed" {
-				downloadURL = statusData.DownloadURL
-				break
-			} else if statusData.Status == "failed" {
-				fmt.Println("Export task failed.")
-				return
+			if !errors.Is(err, rapiddns.ErrExportFailed) {
+				slog.Info("check the task later", "task_id", taskID, "hint", "rapiddns export status "+taskID)
 			}
-
-			time.Sleep(2 * time.Second)
-		}
-
-		if downloadURL == "" {
-			fmt.Println("Task completed but no download URL found.")
-			return
+			return err
 		}
+		downloadURL := final.DownloadURL
 
 		// 3. Download File
-		resultDir := "result"
+		resultDir := config.GetResultDir()
 		if err := os.MkdirAll(resultDir, 0755); err != nil {
-			fmt.Printf("Error creating result directory: %v\n", err)
-			return
+			return fmt.Errorf("creating result directory: %w", err)
 		}
 
 		// Extract filename from URL or generate one
@@ -111,30 +105,27 @@ Can optionally extract subdomains and IPs from the downloaded result (CSV only).
 		}
 
 		destPath := filepath.Join(resultDir, fileName)
-		fmt.Printf("Downloading result to %s...\n", destPath)
+		slog.Info("downloading result", "file", destPath)
 
-		if err := client.DownloadFile(downloadURL, destPath); err != nil {
-			fmt.Printf("Error downloading file: %v\n", err)
-			return
+		if err := client.DownloadFile(ctx, downloadURL, destPath); err != nil {
+			return fmt.Errorf("downloading export: %w", err)
 		}
 
-		fmt.Println("Download completed successfully!")
+		slog.Info("download completed", "file", destPath)
 
 		var extractedCSVPath string
 		// 4. Decompress if needed
 		if exportCompress && strings.HasSuffix(strings.ToLower(fileName), ".zip") {
-			fmt.Printf("Decompressing %s...\n", fileName)
+			slog.Info("decompressing", "file", destPath)
 			unzippedFiles, err := unzip(destPath, resultDir)
 			if err != nil {
-				fmt.Printf("Error decompressing file: %v\n", err)
-			} else {
-				fmt.Println("Decompressed files:")
-				for _, f := range unzippedFiles {
-					fmt.Printf("- %s\n", f)
-					// Try to find the CSV file
-					if strings.HasSuffix(strings.ToLower(f), ".csv") {
-						extractedCSVPath = f
-					}
+				return fmt.Errorf("decompressing %s: %w", destPath, err)
+			}
+			slog.Info("decompressed files", "files", unzippedFiles)
+			for _, f := range unzippedFiles {
+				// Try to find the CSV file
+				if strings.HasSuffix(strings.ToLower(f), ".csv") {
+					extractedCSVPath = f
 				}
 			}
 		} else if strings.HasSuffix(strings.ToLower(fileName), ".csv") {
@@ -143,35 +134,38 @@ Can optionally extract subdomains and IPs from the downloaded result (CSV only).
 
 		// 5. Extract Subdomains and IPs from CSV
 		if (exportExtract || exportExtractIPs) && extractedCSVPath != "" {
-			fmt.Println("Processing CSV for extraction...")
 			records, err := parseCSV(extractedCSVPath)
 			if err != nil {
-				fmt.Printf("Error parsing CSV for extraction: %v\n", err)
-			} else {
-				// Convert to api.SearchData format for reuse of extraction logic
-				// Note: parseCSV returns []api.Record
-				searchData := &api.SearchData{
-					Data: records,
-				}
-				
-				safeKeyword := sanitizeFilename(queryInput)
+				return fmt.Errorf("parsing %s for extraction: %w", extractedCSVPath, err)
+			}
+			// Convert to rapiddns.SearchData format for reuse of extraction logic
+			// Note: parseCSV returns []rapiddns.Record
+			searchData := &rapiddns.SearchData{
+				Data: records,
+			}
+
+			safeKeyword := sanitizeFilename(queryInput)
 
-				if exportExtract {
-					subFile := filepath.Join(resultDir, fmt.Sprintf("%s_subdomains.txt", safeKeyword))
-					extractSubdomains(searchData, subFile)
+			if exportExtract {
+				subFile := filepath.Join(resultDir, fmt.Sprintf("%s_subdomains.txt", safeKeyword))
+				if err := extractSubdomains(searchData, subFile); err != nil {
+					return err
 				}
-				
-				if exportExtractIPs {
-					ipFile := filepath.Join(resultDir, fmt.Sprintf("%s_ips.txt", safeKeyword))
-					statsFile := filepath.Join(resultDir, fmt.Sprintf("%s_ip_stats.txt", safeKeyword))
-					extractIPs(searchData, ipFile, statsFile)
+			}
+
+			if exportExtractIPs {
+				ipFile := filepath.Join(resultDir, fmt.Sprintf("%s_ips.txt", safeKeyword))
+				statsFile := filepath.Join(resultDir, fmt.Sprintf("%s_ip_stats.txt", safeKeyword))
+				if err := extractIPs(searchData, ipFile, statsFile); err != nil {
+					return err
 				}
 			}
 		} else if (exportExtract || exportExtractIPs) && extractedCSVPath == "" {
-			fmt.Println("Warning: Could not find a CSV file to extract data from.")
+			slog.Warn("could not find a CSV file to extract data from")
 		}
 
-		fmt.Println("Export task finished.")
+		slog.Info("export task finished", "task_id", taskID, "retries", client.Retries())
+		return nil
 	},
 }
 
@@ -229,7 +223,7 @@ func unzip(src string, destDir string) ([]string, error) {
 }
 
 // parseCSV reads the exported CSV file and returns records
-func parseCSV(filePath string) ([]api.Record, error) {
+func parseCSV(filePath string) ([]rapiddns.Record, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -245,7 +239,7 @@ func parseCSV(filePath string) ([]api.Record, error) {
 		return nil, err
 	}
 
-	var records []api.Record
+	var records []rapiddns.Record
 	if len(rawRecords) < 2 {
 		return records, nil // Empty or header only
 	}
@@ -279,7 +273,7 @@ func parseCSV(filePath string) ([]api.Record, error) {
 		
 		if firstRowIsData {
 			// Process the first row as data
-			rec := api.Record{}
+			rec := rapiddns.Record{}
 			if len(header) > subdomainIdx { rec.Subdomain = header[subdomainIdx] }
 			if len(header) > typeIdx { rec.Type = header[typeIdx] }
 			if len(header) > valueIdx { rec.Value = header[valueIdx] }
@@ -289,7 +283,7 @@ func parseCSV(filePath string) ([]api.Record, error) {
 	}
 
 	for _, row := range rawRecords[1:] {
-		rec := api.Record{}
+		rec := rapiddns.Record{}
 		if subdomainIdx != -1 && len(row) > subdomainIdx {
 			rec.Subdomain = row[subdomainIdx]
 		}
@@ -311,25 +305,27 @@ func parseCSV(filePath string) ([]api.Record, error) {
 var exportStatusCmd = &cobra.Command{
 	Use:   "status [task_id]",
 	Short: "Check the status of an export task",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if config.GetAPIKey() == "" {
-			fmt.Println("Error: API key is required to check export status.")
-			fmt.Println("If you are not a PRO or MAX member, please purchase a plan at: https://rapiddns.io/pricing")
-			fmt.Println("Then configure your API key using: rapiddns config set-key <YOUR_API_KEY>")
-			return
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := config.GetAPIKey()
+		if err != nil {
+			return err
+		}
+		if apiKey == "" {
+			return fmt.Errorf("%w to check export status", errAPIKeyRequired)
 		}
 		taskID := args[0]
-		client := api.NewClient()
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
 
-		data, err := client.CheckExportStatus(taskID)
+		data, err := client.CheckExportStatus(cmd.Context(), taskID)
 		if err != nil {
-			fmt.Printf("Error checking export status: %v\n", err)
-			return
+			return fmt.Errorf("checking export status: %w", err)
 		}
 
-		output, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(output))
+		return printJSON(data)
 	},
 }
 
@@ -343,4 +339,6 @@ func init() {
 	exportStartCmd.Flags().BoolVar(&exportCompress, "compress", true, "Compress result as ZIP")
 	exportStartCmd.Flags().BoolVar(&exportExtract, "extract-subdomains", false, "Extract and dedup subdomains from exported result")
 	exportStartCmd.Flags().BoolVar(&exportExtractIPs, "extract-ips", false, "Extract and dedup IPs from exported result")
+
+	bindConfigDefault(exportStartCmd, "max", config.ExportMax)
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the CLI interactively",
+	Long: `Asks for the API key, without echoing it, and the most common settings, checks
+the key against the API and writes them, commented, to the config file. Run it
+again to change the answers or with a profile name to set up another profile.`,
+	Args: exactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageErrorf("config init is interactive, use 'rapiddns config set' in scripts")
+		}
+		path, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		w := &wizard{ctx: cmd.Context(), in: bufio.NewReader(os.Stdin), out: os.Stderr}
+		fmt.Fprintf(w.out, "This writes the settings to %s. Press Enter to keep the value in brackets.\n\n", path)
+
+		profile, err := w.askProfile()
+		if err != nil {
+			return err
+		}
+		values := map[string]interface{}{}
+		if err := w.askAPIKey(values); err != nil {
+			return err
+		}
+		for _, key := range []string{config.Output, config.ResultDir, config.PageSize} {
+			if values[key], err = w.askSetting(key); err != nil {
+				return err
+			}
+		}
+		makeDefault := false
+		if profile != "" && profile != config.ActiveProfile() {
+			if makeDefault, err = w.confirm(fmt.Sprintf("Use profile %s by default?", profile), true); err != nil {
+				return err
+			}
+		}
+
+		if err := config.WriteSettings(profile, values); err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+		if makeDefault {
+			if err := config.UseProfile(profile); err != nil {
+				return fmt.Errorf("selecting profile: %w", err)
+			}
+		}
+		attrs := []interface{}{"path", path}
+		if profile != "" {
+			attrs = append(attrs, "profile", profile)
+		}
+		slog.Info("configuration written", attrs...)
+		slog.Info("check the setup with: rapiddns config doctor")
+		return nil
+	},
+}
+
+// wizard asks the questions of config init on the terminal
+type wizard struct {
+	ctx context.Context
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question with the default answer def and returns the answer
+func (w *wizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	line, err := w.in.ReadString('\n')
+	if err := w.ctx.Err(); err != nil {
+		return "", err
+	}
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// confirm asks a yes or no question
+func (w *wizard) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := w.ask(question+" ("+hint+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(w.out, "Please answer y or n.")
+	}
+}
+
+// askProfile asks for the profile to set up, empty for the top level settings
+func (w *wizard) askProfile() (string, error) {
+	for {
+		name, err := w.ask("Profile name, empty for the default settings", config.ActiveProfile())
+		if err != nil || name == "" {
+			return name, err
+		}
+		if err := config.ValidateProfileName(name); err != nil {
+			fmt.Fprintln(w.out, err)
+			continue
+		}
+		return name, nil
+	}
+}
+
+// askSetting asks for the value of the setting key until it is valid
+func (w *wizard) askSetting(key string) (interface{}, error) {
+	s, _ := config.LookupSetting(key)
+	def, _ := s.Value()
+	question := s.Description
+	if len(s.Choices) > 0 {
+		question += " (" + strings.Join(s.Choices, ", ") + ")"
+	}
+	for {
+		answer, err := w.ask(question, def)
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.Parse(answer)
+		if err != nil {
+			fmt.Fprintln(w.out, err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// askAPIKey asks for the API key until the API accepts it or the user skips
+// it, and whether to encrypt it. The key goes into values in the chosen form.
+func (w *wizard) askAPIKey(values map[string]interface{}) error {
+	fmt.Fprintln(w.out, "Get your API key from https://rapiddns.io/user/profile")
+	prompt := "API key (hidden, empty to skip): "
+	source, _ := config.APIKeySource()
+	hasKey := source != ""
+	if hasKey {
+		prompt = "API key (hidden, empty to keep the current one): "
+	}
+	var key string
+	for {
+		var err error
+		if key, err = config.ReadSecret(prompt); err != nil {
+			return fmt.Errorf("reading API key: %w", err)
+		}
+		if key == "" {
+			if !hasKey {
+				fmt.Fprintln(w.out, "Skipped. Without an API key search results are limited and export is disabled.")
+			}
+			return nil
+		}
+
+		err = w.validateAPIKey(key)
+		if err == nil {
+			fmt.Fprintln(w.out, "The API key is valid.")
+			break
+		}
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if errors.Is(err, rapiddns.ErrUnauthorized) {
+			fmt.Fprintln(w.out, "The API key was rejected, please check it and try again.")
+			continue
+		}
+		fmt.Fprintf(w.out, "Could not check the API key: %v\n", err)
+		keep, err := w.confirm("Save it anyway?", true)
+		if err != nil {
+			return err
+		}
+		if keep {
+			break
+		}
+	}
+
+	encrypt, err := w.confirm("Encrypt the API key with a passphrase?", false)
+	if err != nil {
+		return err
+	}
+	if !encrypt {
+		values[config.APIKey] = key
+		return nil
+	}
+	passphrase, err := config.Passphrase(true)
+	if err != nil {
+		return err
+	}
+	if values[config.APIKeyEncrypted], err = config.EncryptAPIKey(key, passphrase); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateAPIKey sends a single record search with key
+func (w *wizard) validateAPIKey(key string) error {
+	client, err := newClientWithKey(key, probeOptions()...)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Search(w.ctx, doctorKeyword, 1, 1, "")
+	return err
+}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var (
+	logLevel  string
+	logFormat string
+	logFile   string
+)
+
+// logOutput is the file logs are written to, if --log-file is set
+var logOutput *os.File
+
+// logSetupErr is reported by validateGlobalFlags, as initLogging cannot return errors
+var logSetupErr error
+
+// initLogging installs the default slog logger configured by --log-level,
+// --log-format and --log-file. It runs before the config file is read so that
+// config diagnostics are logged too.
+func initLogging() {
+	logger, err := newLogger()
+	if err != nil {
+		logSetupErr = err
+		return
+	}
+	slog.SetDefault(logger)
+}
+
+func newLogger() (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return nil, usageErrorf("invalid --log-level %q (use debug, info, warn or error)", logLevel)
+	}
+
+	var w io.Writer = os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("opening log file: %w", err)
+		}
+		logOutput = f
+		w = f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(logFormat) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		if logFile == "" {
+			// Timestamps are noise on an interactive terminal
+			opts.ReplaceAttr = dropTime
+		}
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+	return nil, usageErrorf("invalid --log-format %q (use text or json)", logFormat)
+}
+
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// closeLog closes the log file, if one was opened
+func closeLog() {
+	if logOutput != nil {
+		logOutput.Close()
+	}
+}
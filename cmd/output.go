@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
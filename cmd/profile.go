@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileAPIKey    string
+	profileBaseURL   string
+	profileResultDir string
+	profileUse       bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named configuration profiles",
+	Long: `Profiles keep separate settings, such as API key, endpoint and result directory,
+in the same config file. Select a profile for one run with --profile or RAPIDDNS_PROFILE,
+or make it the default with 'rapiddns config profile use'. Settings missing from a
+profile fall back to the top level settings of the config file.`,
+	Args: noSubcommand,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Create a profile",
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		settings := map[string]interface{}{}
+		// Validated like rapiddns config set, so the profile is usable once added
+		for key, value := range map[string]string{
+			config.APIKey:    profileAPIKey,
+			config.BaseURL:   profileBaseURL,
+			config.ResultDir: profileResultDir,
+		} {
+			if value == "" {
+				continue
+			}
+			s, err := lookupSetting(key)
+			if err != nil {
+				return err
+			}
+			parsed, err := s.Parse(value)
+			if err != nil {
+				return usageError{err}
+			}
+			settings[key] = parsed
+		}
+		if err := config.AddProfile(name, settings); err != nil {
+			return profileError("adding profile", err)
+		}
+		slog.Info("profile added", "profile", name)
+
+		if profileUse {
+			if err := config.UseProfile(name); err != nil {
+				return profileError("selecting profile", err)
+			}
+			slog.Info("profile is now the default", "profile", name)
+		}
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the active one with *",
+	Args:  exactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		active := config.ActiveProfile()
+		for _, name := range config.ProfileNames() {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Make a profile the default",
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.UseProfile(args[0]); err != nil {
+			return profileError("selecting profile", err)
+		}
+		slog.Info("profile is now the default", "profile", args[0])
+		return nil
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Delete a profile",
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.RemoveProfile(args[0]); err != nil {
+			return profileError("removing profile", err)
+		}
+		slog.Info("profile removed", "profile", args[0])
+		return nil
+	},
+}
+
+// profileError wraps an error of action, marking unknown, existing and invalid
+// profile names as usage errors
+func profileError(action string, err error) error {
+	err = fmt.Errorf("%s: %w", action, err)
+	if errors.Is(err, config.ErrUnknownProfile) || errors.Is(err, config.ErrProfileExists) ||
+		errors.Is(err, config.ErrInvalidProfileName) {
+		return usageError{err}
+	}
+	return err
+}
+
+func init() {
+	configCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileRemoveCmd)
+
+	profileAddCmd.Flags().StringVar(&profileAPIKey, "api-key", "", "API key of the profile")
+	profileAddCmd.Flags().StringVar(&profileBaseURL, "base-url", "", "API endpoint of the profile")
+	profileAddCmd.Flags().StringVar(&profileResultDir, "result-dir", "", "Directory result files of the profile are written to")
+	profileAddCmd.Flags().BoolVar(&profileUse, "use", false, "Make the new profile the default")
+}
@@ -1,17 +1,18 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"rapiddns-cli/internal/api"
-	"rapiddns-cli/internal/config"
 
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
 	"github.com/spf13/cobra"
 )
 
 var (
 	queryPage     int
 	queryPageSize int
+	queryMax      int
+	queryWorkers  int
 )
 
 var queryCmd = &cobra.Command{
@@ -21,25 +22,33 @@ var queryCmd = &cobra.Command{
 Examples:
   rapiddns query 'domain:apple AND tld:com'
   rapiddns query 'type:A AND value:"172.217.3.174"'`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if config.GetAPIKey() == "" {
-			fmt.Println("Warning: No API key configured. Results may be limited.")
-			fmt.Println("If you are not a PRO or MAX member, please purchase a plan at: https://rapiddns.io/pricing")
-			fmt.Println("Then configure your API key using: rapiddns config set-key <YOUR_API_KEY>")
-			fmt.Println("")
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := config.GetAPIKey()
+		if err != nil {
+			return err
+		}
+		if apiKey == "" && !offlineMode {
+			warnNoAPIKey()
 		}
 		query := args[0]
-		client := api.NewClient()
-
-		_, data, err := client.AdvancedQuery(query, queryPage, queryPageSize)
+		client, err := newClient()
 		if err != nil {
-			fmt.Printf("Error querying: %v\n", err)
-			return
+			return err
 		}
 
-		output, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(output))
+		ctx := cmd.Context()
+		opts := rapiddns.PageOptions{Page: queryPage, PageSize: queryPageSize, Max: queryMax, Concurrency: queryWorkers}
+		data, err := collectRecords(ctx, client.QueryAll(ctx, query, opts), false, queryPageSize)
+		if err != nil && !isResultErr(err) {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		resultErr := err
+
+		if err := printJSON(data); err != nil {
+			return err
+		}
+		return resultErr
 	},
 }
 
@@ -47,4 +56,10 @@ func init() {
 	rootCmd.AddCommand(queryCmd)
 	queryCmd.Flags().IntVar(&queryPage, "page", 1, "Page index to fetch")
 	queryCmd.Flags().IntVar(&queryPageSize, "pagesize", 100, "Page size per request")
+	queryCmd.Flags().IntVar(&queryMax, "max", 10000, "Max records to fetch (pagination will be handled automatically)")
+	queryCmd.Flags().IntVar(&queryWorkers, "concurrency", 1, "Pages fetched in parallel once the total is known")
+
+	bindConfigDefault(queryCmd, "pagesize", config.PageSize)
+	bindConfigDefault(queryCmd, "max", config.Max)
+	bindConfigDefault(queryCmd, "concurrency", config.Concurrency)
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show local API usage counters and the last known plan quota",
+	Long: `Shows the number of API requests sent by this machine, as recorded across runs,
+together with the configured client side limits and the plan quota last reported by the API.
+
+Limits are configured in the config file:
+  requests_per_second: 2
+  requests_per_day: 1000`,
+	Args: exactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		u := loadUsage()
+
+		perDay := config.GetRequestsPerDay()
+		if perDay > 0 {
+			fmt.Printf("Requests today:      %d / %d\n", u.RequestsToday(), perDay)
+		} else {
+			fmt.Printf("Requests today:      %d (no daily limit)\n", u.RequestsToday())
+		}
+		fmt.Printf("Requests total:      %d\n", u.Total)
+
+		if perSecond := config.GetRequestsPerSecond(); perSecond > 0 {
+			fmt.Printf("Rate limit:          %g requests/sec\n", perSecond)
+		} else {
+			fmt.Println("Rate limit:          none")
+		}
+
+		if u.Quota == nil {
+			fmt.Println("Plan quota:          not reported by the API yet")
+			return
+		}
+		fmt.Printf("Plan quota:          %d remaining of %d\n", u.Quota.Remaining, u.Quota.Limit)
+		if u.Quota.Reset != "" {
+			fmt.Printf("Quota reset:         %s\n", u.Quota.Reset)
+		}
+		fmt.Printf("Quota last updated:  %s\n", u.Quota.UpdatedAt.Format("2006-01-02 15:04:05"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+}
@@ -1,11 +1,36 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-	"rapiddns-cli/internal/config"
+	"os/signal"
+	"time"
 
+	"github.com/rapiddns/rapiddns-cli/internal/cache"
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/internal/version"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var (
+	requestTimeout time.Duration
+	requestRetries int
+	strictDecode   bool
+	noCache        bool
+	refreshCache   bool
+	offlineMode    bool
+	configFile     string
+	errorFormat    string
+	traceHTTP      bool
+	traceFile      string
+	traceBodies    bool
+	dumpDir        string
 )
 
 var rootCmd = &cobra.Command{
@@ -13,15 +38,278 @@ var rootCmd = &cobra.Command{
 	Short: "RapidDNS CLI - A command line interface for RapidDNS API",
 	Long: `RapidDNS CLI allows you to query DNS data, search domains, IPs, and export results
 directly from your terminal using the RapidDNS API.`,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: validateGlobalFlags,
+	Args:              noSubcommand,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Cancel in-flight requests on Ctrl-C. Once the first interrupt has been
+	// delivered the default handler is restored, so a second Ctrl-C exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	saveUsage()
+	closeTrace()
+	closeLog()
+	if err != nil {
+		reportError(err)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
-	cobra.OnInitialize(config.InitConfig)
+	cobra.OnInitialize(initLogging, func() { config.InitConfig(configFile) })
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default $XDG_CONFIG_HOME/rapiddns/config.yaml or ~/.rapiddns.yaml, env RAPIDDNS_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "Format of the error written to stderr on failure: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 0, "Deadline for each API request, e.g. 30s (0 means no deadline)")
+	rootCmd.PersistentFlags().BoolVar(&strictDecode, "strict", false, "Fail on API responses with an unexpected shape instead of working around them")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the response cache")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore cached responses but update the cache with fresh ones")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Answer search and query from the response cache only, without network access")
+	rootCmd.PersistentFlags().BoolVar(&traceHTTP, "trace", false, "Trace HTTP requests and responses to stderr (the API key is redacted)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Write the HTTP trace to this file instead of stderr (implies --trace)")
+	rootCmd.PersistentFlags().BoolVar(&traceBodies, "trace-bodies", false, "Include request and response bodies in the HTTP trace")
+	rootCmd.PersistentFlags().StringVar(&dumpDir, "dump-responses", "", "Save every raw API response to this directory, e.g. for bug reports")
+	rootCmd.PersistentFlags().IntVar(&requestRetries, "retries", rapiddns.DefaultRetryPolicy.MaxAttempts-1, "Retries for failed requests (429, 5xx and network errors)")
+
+	rootCmd.PersistentFlags().String("profile", "", "Configuration profile to use (env RAPIDDNS_PROFILE)")
+	viper.BindPFlag(config.Profile, rootCmd.PersistentFlags().Lookup("profile"))
+
+	// Connection settings, also configurable in the config file and environment
+	rootCmd.PersistentFlags().String("base-url", "", "API endpoint (default "+rapiddns.BaseURL+")")
+	rootCmd.PersistentFlags().String("proxy", "", "HTTP(S) or SOCKS5 proxy URL, e.g. socks5://127.0.0.1:1080")
+	rootCmd.PersistentFlags().String("ca-cert", "", "PEM CA bundle to trust in addition to the system roots")
+	rootCmd.PersistentFlags().String("client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().String("client-key", "", "PEM private key of the client certificate")
+	rootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS certificate verification (not recommended)")
+	for key, flag := range map[string]string{
+		config.BaseURL:     "base-url",
+		config.Proxy:       "proxy",
+		config.CACert:      "ca-cert",
+		config.ClientCert:  "client-cert",
+		config.ClientKey:   "client-key",
+		config.InsecureTLS: "insecure",
+	} {
+		viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(flag))
+	}
+}
+
+// validateGlobalFlags rejects contradictory combinations of global flags
+func validateGlobalFlags(cmd *cobra.Command, args []string) error {
+	if logSetupErr != nil {
+		return logSetupErr
+	}
+	// Configuration commands must keep working to fix a broken configuration
+	if err := config.Err(); err != nil && !isSubcommand(cmd, configCmd) {
+		if errors.Is(err, config.ErrUnknownProfile) {
+			return usageError{err}
+		}
+		return err
+	}
+	if errorFormat != "text" && errorFormat != "json" {
+		return usageErrorf("invalid --error-format %q (use text or json)", errorFormat)
+	}
+	if err := applyConfigDefaults(cmd); err != nil {
+		return err
+	}
+	if offlineMode && noCache {
+		return usageErrorf("--offline answers from the response cache and cannot be combined with --no-cache")
+	}
+	return nil
+}
+
+// configKeyAnnotation names the setting a flag takes its default from
+const configKeyAnnotation = "rapiddns_config_key"
+
+// bindConfigDefault makes the setting key the default of the flag name of cmd
+func bindConfigDefault(cmd *cobra.Command, name, key string) {
+	cmd.Flags().SetAnnotation(name, configKeyAnnotation, []string{key})
+}
+
+// applyConfigDefaults sets the flags of cmd bound with bindConfigDefault that
+// were not given on the command line to the value of their setting, if it is set
+// in the config file or environment
+func applyConfigDefaults(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		keys := f.Annotations[configKeyAnnotation]
+		if err != nil || len(keys) == 0 || f.Changed || !viper.IsSet(keys[0]) {
+			return
+		}
+		if setErr := f.Value.Set(viper.GetString(keys[0])); setErr != nil {
+			err = usageErrorf("invalid %s in config: %v", keys[0], setErr)
+		}
+	})
+	return err
+}
+
+// isSubcommand reports whether cmd is parent or one of its descendants
+func isSubcommand(cmd, parent *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// newClient creates an API client configured from the global flags and the config file
+func newClient() (*rapiddns.Client, error) {
+	apiKey, err := config.GetAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	return newClientWithKey(apiKey)
+}
+
+// newClientWithKey is newClient using apiKey instead of the configured key. The
+// overrides are applied last and win over the options taken from the flags.
+func newClientWithKey(apiKey string, overrides ...rapiddns.Option) (*rapiddns.Client, error) {
+	policy := rapiddns.DefaultRetryPolicy
+	policy.MaxAttempts = requestRetries + 1
+
+	opts := []rapiddns.Option{
+		rapiddns.WithAPIKey(apiKey),
+		rapiddns.WithBaseURL(config.GetBaseURL()),
+		rapiddns.WithUserAgent(version.UserAgent()),
+		rapiddns.WithLogger(slog.Default()),
+		rapiddns.WithTimeout(requestTimeout),
+		rapiddns.WithRetryPolicy(policy),
+		rapiddns.WithStrict(strictDecode),
+		rapiddns.WithOffline(offlineMode),
+		rapiddns.WithRateLimit(config.GetRequestsPerSecond(), config.GetRequestsPerDay()),
+		rapiddns.WithUsage(loadUsage()),
+	}
+
+	if proxy := config.GetProxy(); proxy != "" {
+		proxyURL, err := rapiddns.ParseProxyURL(proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, rapiddns.WithProxy(proxyURL))
+	}
+
+	caCert, clientCert, clientKey := config.GetTLSFiles()
+	tlsConfig, err := rapiddns.LoadTLSConfig(rapiddns.TLSOptions{
+		CACert:             caCert,
+		ClientCert:         clientCert,
+		ClientKey:          clientKey,
+		InsecureSkipVerify: config.GetInsecureTLS(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, rapiddns.WithTLSConfig(tlsConfig))
+
+	trace, err := traceOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, rapiddns.WithTrace(trace))
+
+	if !noCache {
+		if rc, err := newCache(); err == nil {
+			opts = append(opts, rapiddns.WithCache(rc, refreshCache))
+		} else {
+			slog.Warn("response cache disabled", "error", err)
+		}
+	}
+	return rapiddns.NewClient(append(opts, overrides...)...), nil
+}
+
+// newCache opens the response cache configured in the config file
+func newCache() (*cache.Cache, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir, config.GetCacheTTL()), nil
+}
+
+// usage holds the request counters shared by all clients of this run
+var usage *rapiddns.Usage
+
+// loadUsage loads the persisted request counters once per run
+func loadUsage() *rapiddns.Usage {
+	if usage != nil {
+		return usage
+	}
+	usage = &rapiddns.Usage{}
+	path, err := config.UsagePath()
+	if err != nil {
+		return usage
+	}
+	if u, err := rapiddns.LoadUsage(path); err == nil {
+		usage = u
+	} else {
+		slog.Warn("could not read usage counters", "error", err)
+	}
+	return usage
+}
+
+// saveUsage persists the request counters if any client was created
+func saveUsage() {
+	if usage == nil {
+		return
+	}
+	path, err := config.UsagePath()
+	if err == nil {
+		err = usage.Save(path)
+	}
+	if err != nil {
+		slog.Warn("could not save usage counters", "error", err)
+	}
+}
+
+// traceOutput is the file the HTTP trace is written to, if --trace-file is set
+var traceOutput *os.File
+
+// traceOptions builds the HTTP trace settings from the global flags. The trace
+// file is opened once per run and appended to.
+func traceOptions() (rapiddns.TraceOptions, error) {
+	opts := rapiddns.TraceOptions{Bodies: traceBodies, DumpDir: dumpDir}
+	switch {
+	case traceFile != "":
+		if traceOutput == nil {
+			f, err := os.OpenFile(traceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return opts, fmt.Errorf("opening trace file: %w", err)
+			}
+			traceOutput = f
+		}
+		opts.Output = traceOutput
+	case traceHTTP || traceBodies:
+		opts.Output = os.Stderr
+	}
+	return opts, nil
+}
+
+// closeTrace closes the trace file, if one was opened
+func closeTrace() {
+	if traceOutput != nil {
+		traceOutput.Close()
+	}
+}
+
+// warnNoAPIKey tells users of search and query that anonymous results are limited
+func warnNoAPIKey() {
+	slog.Warn("no API key configured, results may be limited",
+		"plans", "https://rapiddns.io/pricing",
+		"hint", "rapiddns config set-key <YOUR_API_KEY>")
 }
@@ -2,18 +2,23 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
+	"io"
+	"iter"
+	"log/slog"
+	"net/netip"
 	"os"
 	"path/filepath"
-	"rapiddns-cli/internal/api"
-	"rapiddns-cli/internal/config"
 	"regexp"
 	"sort"
 	"strings"
 
+	"github.com/rapiddns/rapiddns-cli/internal/config"
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
 	"github.com/spf13/cobra"
 )
 
@@ -28,102 +33,49 @@ var (
 	searchColumn     string
 	searchSilent     bool
 	searchMax        int
+	searchWorkers    int
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search [keyword]",
 	Short: "Search by keyword (domain, IP, or CIDR)",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		if config.GetAPIKey() == "" {
-			fmt.Fprintln(os.Stderr, "Warning: No API key configured. Results may be limited.")
-			fmt.Fprintln(os.Stderr, "If you are not a PRO or MAX member, please purchase a plan at: https://rapiddns.io/pricing")
-			fmt.Fprintln(os.Stderr, "Then configure your API key using: rapiddns config set-key <YOUR_API_KEY>")
-			fmt.Fprintln(os.Stderr, "")
+	Args:  exactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := config.GetAPIKey()
+		if err != nil {
+			return err
+		}
+		if apiKey == "" && !offlineMode {
+			warnNoAPIKey()
 		}
 		keyword := args[0]
-		client := api.NewClient()
-		
-		var data *api.SearchData
-		var err error
+		ctx := cmd.Context()
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
 
-		// Always use pagination loop since default max is 10000
+		// Always paginate since default max is 10000
 		if !searchSilent {
-			fmt.Fprintf(os.Stderr, "Fetching up to %d records...\n", searchMax)
+			slog.Info("fetching records", "keyword", keyword, "max", searchMax)
 		}
-			
-		allRecords := []api.Record{}
-		currentPage := searchPage // Start from specified page
-			
-		for {
-			_, pageData, pageErr := client.Search(keyword, currentPage, searchPageSize, searchType)
-				if pageErr != nil {
-					// If it's the first page and fails, return error
-					if len(allRecords) == 0 {
-						err = pageErr
-					} else {
-						// If subsequent page fails, just stop and use what we have
-						fmt.Fprintf(os.Stderr, "Warning: Stopped fetching at page %d due to error: %v\n", currentPage, pageErr)
-					}
-					break
-				}
-
-				// Extract records from this page
-				var pageRecords []api.Record
-				if len(pageData.Data) > 0 {
-					pageRecords = pageData.Data
-				} else if len(pageData.Result) > 0 {
-					pageRecords = pageData.Result
-				}
-
-				if len(pageRecords) == 0 {
-					break // No more data
-				}
 
-				allRecords = append(allRecords, pageRecords...)
-				
-				if !searchSilent {
-					fmt.Fprintf(os.Stderr, "\rFetched %d records...", len(allRecords))
-				}
-
-				// Check limits
-				if len(allRecords) >= searchMax {
-					// Trim excess
-					allRecords = allRecords[:searchMax]
-					break
-				}
-
-				// Check if this was the last page (less than pageSize returned)
-				// Note: API might return exact pageSize on last page, so this is an approximation.
-				// Reliable way is checking total if available, or just keep fetching until empty.
-				// But empty check is done above.
-				if len(pageRecords) < searchPageSize {
-					break
-				}
-
-				currentPage++
-			}
-			if !searchSilent {
-				fmt.Fprintf(os.Stderr, "\nDone.\n")
-			}
-
-			// Construct combined data
-			data = &api.SearchData{
-				Data:   allRecords,
-				Status: "ok",
-				Total:  len(allRecords),
-			}
+		opts := rapiddns.PageOptions{Page: searchPage, PageSize: searchPageSize, Max: searchMax, Concurrency: searchWorkers}
+		data, err := collectRecords(ctx, client.SearchAll(ctx, keyword, searchType, opts), !searchSilent, searchPageSize)
+		if !searchSilent {
+			slog.Info("search finished", "records", data.Total, "retries", client.Retries())
+		}
 
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error searching: %v\n", err)
-			return
+		if err != nil && !isResultErr(err) {
+			return fmt.Errorf("search failed: %w", err)
 		}
+		// Partial or empty results are still written, then reported through the exit code
+		resultErr := err
 
 		// Ensure result directory exists if we are saving to file
 		if searchExtract || searchExtractIPs || searchOutFile != "" {
-			if err := os.MkdirAll("result", 0755); err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating result directory: %v\n", err)
-				return
+			if err := os.MkdirAll(config.GetResultDir(), 0755); err != nil {
+				return fmt.Errorf("creating result directory: %w", err)
 			}
 		}
 
@@ -142,7 +94,9 @@ var searchCmd = &cobra.Command{
 			}
 			
 			subFile = resolvePath(subFile)
-			extractSubdomains(data, subFile)
+			if err := extractSubdomains(data, subFile); err != nil {
+				return err
+			}
 		}
 
 		// Process IP Extraction
@@ -163,13 +117,17 @@ var searchCmd = &cobra.Command{
 			
 			ipFile = resolvePath(ipFile)
 			statsFile = resolvePath(statsFile)
-			extractIPs(data, ipFile, statsFile)
+			if err := extractIPs(data, ipFile, statsFile); err != nil {
+				return err
+			}
 		}
 
 		// Process Main Output
 		if searchOutFile != "" {
 			finalPath := resolvePath(searchOutFile)
-			saveToFile(data, finalPath, searchOutput)
+			if err := saveToFile(data, finalPath, searchOutput); err != nil {
+				return err
+			}
 		} 
 		
 		// Console Output
@@ -180,8 +138,11 @@ var searchCmd = &cobra.Command{
 		// But user requirement implies flexible control. 
 		// If searchOutFile is empty, we MUST output to console unless silent.
 		if searchOutFile == "" && !searchSilent {
-			printConsoleOutput(data, searchOutput, searchColumn)
+			if err := printConsoleOutput(data, searchOutput, searchColumn); err != nil {
+				return err
+			}
 		}
+		return resultErr
 	},
 }
 
@@ -193,10 +154,77 @@ func init() {
 	searchCmd.Flags().StringVarP(&searchOutput, "output", "o", "json", "Output format: json, csv, text")
 	searchCmd.Flags().BoolVar(&searchExtract, "extract-subdomains", false, "Extract and dedup subdomains to file")
 	searchCmd.Flags().BoolVar(&searchExtractIPs, "extract-ips", false, "Extract and dedup IPs to file with subnet stats")
-	searchCmd.Flags().StringVarP(&searchOutFile, "file", "f", "", "Output file path (default saved to 'result/' directory)")
+	searchCmd.Flags().StringVarP(&searchOutFile, "file", "f", "", "Output file path (relative paths are saved to the result directory, result_dir)")
 	searchCmd.Flags().StringVar(&searchColumn, "column", "", "Output only specific column (subdomain, ip, type, value) to console")
 	searchCmd.Flags().BoolVar(&searchSilent, "silent", false, "Suppress console output")
 	searchCmd.Flags().IntVar(&searchMax, "max", 10000, "Max records to fetch (pagination will be handled automatically)")
+	searchCmd.Flags().IntVar(&searchWorkers, "concurrency", 1, "Pages fetched in parallel once the total is known")
+
+	bindConfigDefault(searchCmd, "pagesize", config.PageSize)
+	bindConfigDefault(searchCmd, "max", config.Max)
+	bindConfigDefault(searchCmd, "output", config.Output)
+	bindConfigDefault(searchCmd, "concurrency", config.Concurrency)
+}
+
+// collectRecords drains a record iterator into a single SearchData. Records from
+// pages that succeeded are kept and each failed page is reported. If nothing was
+// fetched the first error is returned; otherwise missing pages or an interruption
+// yield errPartialResults and an empty result errNoResults, together with the data.
+func collectRecords(ctx context.Context, records iter.Seq2[rapiddns.Record, error], progress bool, pageSize int) (*rapiddns.SearchData, error) {
+	allRecords := []rapiddns.Record{}
+	var failures []*rapiddns.PageError
+	var interrupted error
+
+	for record, err := range records {
+		if err != nil {
+			if ctx.Err() != nil {
+				// Interrupted by the user, keep what we have
+				if len(allRecords) == 0 {
+					return &rapiddns.SearchData{Data: allRecords, Status: "ok"}, err
+				}
+				slog.Warn("interrupted, keeping records fetched so far", "records", len(allRecords))
+				interrupted = err
+				break
+			}
+
+			var pe *rapiddns.PageError
+			if !errors.As(err, &pe) {
+				pe = &rapiddns.PageError{Err: err}
+			}
+			failures = append(failures, pe)
+			continue
+		}
+
+		allRecords = append(allRecords, record)
+		if progress && len(allRecords)%pageSize == 0 {
+			slog.Debug("fetched records", "records", len(allRecords))
+		}
+	}
+
+	// Construct combined data
+	data := &rapiddns.SearchData{
+		Data:   allRecords,
+		Status: "ok",
+		Total:  len(allRecords),
+	}
+
+	if len(failures) > 0 && len(allRecords) == 0 {
+		// Nothing fetched at all, report the first error
+		return data, failures[0]
+	}
+	for _, pe := range failures {
+		// Keep what we have, but say which pages are missing
+		slog.Warn("failed to fetch page", "page", pe.Page, "error", pe.Err)
+	}
+	switch {
+	case interrupted != nil:
+		return data, fmt.Errorf("%w: interrupted after %d records: %w", errPartialResults, len(allRecords), interrupted)
+	case len(failures) > 0:
+		return data, fmt.Errorf("%w: %d page(s) failed", errPartialResults, len(failures))
+	case len(allRecords) == 0:
+		return data, errNoResults
+	}
+	return data, nil
 }
 
 // sanitizeFilename replaces characters that are illegal/unsafe in filenames
@@ -212,32 +240,28 @@ func sanitizeFilename(name string) string {
 	return safe
 }
 
+// resolvePath places relative paths in the result directory, unless they already point into it
 func resolvePath(path string) string {
-	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "result"+string(os.PathSeparator)) && !strings.HasPrefix(path, "result/") {
-		return filepath.Join("result", path)
+	dir := filepath.Clean(config.GetResultDir())
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, dir+string(os.PathSeparator)) && !strings.HasPrefix(path, dir+"/") {
+		return filepath.Join(dir, path)
 	}
 	return path
 }
 
-func extractSubdomains(data *api.SearchData, outFile string) {
+func extractSubdomains(data *rapiddns.SearchData, outFile string) error {
 	subdomains := make(map[string]bool)
-	var records []api.Record
-	if len(data.Data) > 0 {
-		records = data.Data
-	} else if len(data.Result) > 0 {
-		records = data.Result
-	}
+	records := data.Records()
 
 	for _, record := range records {
-		if record.Subdomain != "" {
-			subdomains[record.Subdomain] = true
+		if name := record.Name(); name != "" {
+			subdomains[name] = true
 		}
 	}
 
 	file, err := os.Create(outFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating file: %v\n", err)
-		return
+		return fmt.Errorf("creating subdomain file: %w", err)
 	}
 	defer file.Close()
 
@@ -245,55 +269,48 @@ func extractSubdomains(data *api.SearchData, outFile string) {
 	for sub := range subdomains {
 		fmt.Fprintln(writer, sub)
 	}
-	writer.Flush()
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing subdomain file: %w", err)
+	}
 	
 	absPath, _ := filepath.Abs(outFile)
 	if !searchSilent {
-		fmt.Fprintf(os.Stderr, "Extracted %d unique subdomains to %s\n", len(subdomains), absPath)
+		slog.Info("extracted unique subdomains", "count", len(subdomains), "file", absPath)
 	} else {
 		// Even in silent mode, print the file path to stdout for piping/scripting usage
 		fmt.Println(absPath)
 	}
+	return nil
 }
 
-func extractIPs(data *api.SearchData, ipFile, statsFile string) {
+func extractIPs(data *rapiddns.SearchData, ipFile, statsFile string) error {
 	ips := make(map[string]bool)
-	var records []api.Record
-	if len(data.Data) > 0 {
-		records = data.Data
-	} else if len(data.Result) > 0 {
-		records = data.Result
-	}
+	records := data.Records()
 
 	subnetStats := make(map[string]int)
 
 	for _, record := range records {
-		val := record.Value
-		if net.ParseIP(val) != nil {
-			if !ips[val] {
-				ips[val] = true
-				
-				ip := net.ParseIP(val)
-				if ip.To4() != nil {
-					mask := net.CIDRMask(24, 32)
-					maskedIP := ip.Mask(mask)
-					subnet := maskedIP.String() + "/24"
-					subnetStats[subnet]++
-				} else {
-					mask := net.CIDRMask(64, 128)
-					maskedIP := ip.Mask(mask)
-					subnet := maskedIP.String() + "/64"
-					subnetStats[subnet]++
-				}
+		addr, ok := record.Addr()
+		if !ok {
+			continue
+		}
+		val := addr.String()
+		if !ips[val] {
+			ips[val] = true
+
+			bits := 64
+			if addr.Is4() {
+				bits = 24
 			}
+			subnet := netip.PrefixFrom(addr, bits).Masked()
+			subnetStats[subnet.String()]++
 		}
 	}
 
 	// Write IPs to file
 	file, err := os.Create(ipFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating IP file: %v\n", err)
-		return
+		return fmt.Errorf("creating IP file: %w", err)
 	}
 	defer file.Close()
 
@@ -308,11 +325,13 @@ func extractIPs(data *api.SearchData, ipFile, statsFile string) {
 	for _, ip := range sortedIPs {
 		fmt.Fprintln(writer, ip)
 	}
-	writer.Flush()
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing IP file: %w", err)
+	}
 	
 	ipAbsPath, _ := filepath.Abs(ipFile)
 	if !searchSilent {
-		fmt.Fprintf(os.Stderr, "Extracted %d unique IPs to %s\n", len(ips), ipAbsPath)
+		slog.Info("extracted unique IPs", "count", len(ips), "file", ipAbsPath)
 	} else {
 		fmt.Println(ipAbsPath)
 	}
@@ -320,8 +339,7 @@ func extractIPs(data *api.SearchData, ipFile, statsFile string) {
 	// Write Stats to file
 	sFile, err := os.Create(statsFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating Stats file: %v\n", err)
-		return
+		return fmt.Errorf("creating IP statistics file: %w", err)
 	}
 	defer sFile.Close()
 
@@ -336,11 +354,13 @@ func extractIPs(data *api.SearchData, ipFile, statsFile string) {
 	for _, subnet := range sortedSubnets {
 		fmt.Fprintf(sWriter, "%s: %d IPs\n", subnet, subnetStats[subnet])
 	}
-	sWriter.Flush()
+	if err := sWriter.Flush(); err != nil {
+		return fmt.Errorf("writing IP statistics file: %w", err)
+	}
 	
 	statsAbsPath, _ := filepath.Abs(statsFile)
 	if !searchSilent {
-		fmt.Fprintf(os.Stderr, "Extracted IP statistics to %s\n", statsAbsPath)
+		slog.Info("extracted IP statistics", "file", statsAbsPath)
 	} else {
 		fmt.Println(statsAbsPath)
 	}
@@ -352,60 +372,64 @@ func extractIPs(data *api.SearchData, ipFile, statsFile string) {
 			fmt.Fprintf(os.Stderr, "  %s: %d\n", subnet, subnetStats[subnet])
 		}
 	}
+	return nil
 }
 
-func saveToFile(data *api.SearchData, outFile, format string) {
+func saveToFile(data *rapiddns.SearchData, outFile, format string) error {
+	format = strings.ToLower(format)
+	switch format {
+	case "json", "csv", "text":
+	default:
+		return usageErrorf("unknown output format %q (use json, csv or text)", format)
+	}
+
 	file, err := os.Create(outFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating file: %v\n", err)
-		return
+		return fmt.Errorf("creating output file: %w", err)
 	}
 	defer file.Close()
 
-	var records []api.Record
-	if len(data.Data) > 0 {
-		records = data.Data
-	} else if len(data.Result) > 0 {
-		records = data.Result
+	if err := writeRecords(file, data, format); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
 	}
 
+	absPath, _ := filepath.Abs(outFile)
+	if !searchSilent {
+		slog.Info("saved output", "file", absPath)
+	} else {
+		fmt.Println(absPath)
+	}
+	return nil
+}
+
+// writeRecords writes data to w in the given output format; unknown formats are written as JSON
+func writeRecords(w io.Writer, data *rapiddns.SearchData, format string) error {
+	records := data.Records()
+
 	switch strings.ToLower(format) {
-	case "json":
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		encoder.Encode(data)
 	case "csv":
-		writer := csv.NewWriter(file)
-		defer writer.Flush()
+		writer := csv.NewWriter(w)
 		writer.Write([]string{"Subdomain", "Type", "Value", "Date", "Timestamp"})
 		for _, r := range records {
 			writer.Write([]string{r.Subdomain, r.Type, r.Value, r.Date, r.Timestamp})
 		}
+		writer.Flush()
+		return writer.Error()
 	case "text":
-		writer := bufio.NewWriter(file)
-		defer writer.Flush()
+		writer := bufio.NewWriter(w)
 		for _, r := range records {
 			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", r.Subdomain, r.Type, r.Value, r.Date)
 		}
+		return writer.Flush()
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", format)
-	}
-	
-	absPath, _ := filepath.Abs(outFile)
-	if !searchSilent {
-		fmt.Fprintf(os.Stderr, "Saved output to %s\n", absPath)
-	} else {
-		fmt.Println(absPath)
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
 	}
 }
 
-func printConsoleOutput(data *api.SearchData, format, column string) {
-	var records []api.Record
-	if len(data.Data) > 0 {
-		records = data.Data
-	} else if len(data.Result) > 0 {
-		records = data.Result
-	}
+func printConsoleOutput(data *rapiddns.SearchData, format, column string) error {
+	records := data.Records()
 
 	// If a column is specified, we filter the data first
 	if column != "" {
@@ -420,9 +444,8 @@ func printConsoleOutput(data *api.SearchData, format, column string) {
 			case "subdomain":
 				val = r.Subdomain
 			case "ip":
-				// Attempt to extract IP from value
-				if net.ParseIP(r.Value) != nil {
-					val = r.Value
+				if addr, ok := r.Addr(); ok {
+					val = addr.String()
 				}
 			case "value":
 				val = r.Value
@@ -440,38 +463,16 @@ func printConsoleOutput(data *api.SearchData, format, column string) {
 		// Print based on format
 		if strings.ToLower(format) == "json" {
 			// Print as JSON array
-			output, _ := json.MarshalIndent(values, "", "  ")
-			fmt.Println(string(output))
-		} else {
-			// Text/CSV: just print lines for single column
-			for _, v := range values {
-				fmt.Println(v)
-			}
+			return printJSON(values)
 		}
-		return
-	}
-
-	// Standard full output
-	switch strings.ToLower(format) {
-	case "json":
-		output, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(output))
-	case "csv":
-		writer := csv.NewWriter(os.Stdout)
-		defer writer.Flush()
-		writer.Write([]string{"Subdomain", "Type", "Value", "Date", "Timestamp"})
-		for _, r := range records {
-			writer.Write([]string{r.Subdomain, r.Type, r.Value, r.Date, r.Timestamp})
-		}
-	case "text":
+		// Text/CSV: just print lines for single column
 		writer := bufio.NewWriter(os.Stdout)
-		defer writer.Flush()
-		for _, r := range records {
-			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", r.Subdomain, r.Type, r.Value, r.Date)
+		for _, v := range values {
+			fmt.Fprintln(writer, v)
 		}
-	default:
-		// Default to JSON if unknown
-		output, _ := json.MarshalIndent(data, "", "  ")
-		fmt.Println(string(output))
+		return writer.Flush()
 	}
+
+	// Standard full output
+	return writeRecords(os.Stdout, data, format)
 }
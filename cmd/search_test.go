@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+)
+
+// pages yields the records and errors of results in order, like SearchAll
+func pages(results ...any) iter.Seq2[rapiddns.Record, error] {
+	return func(yield func(rapiddns.Record, error) bool) {
+		for _, r := range results {
+			var ok bool
+			switch r := r.(type) {
+			case error:
+				ok = yield(rapiddns.Record{}, r)
+			case rapiddns.Record:
+				ok = yield(r, nil)
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func TestCollectRecords(t *testing.T) {
+	www := rapiddns.Record{Subdomain: "www.example.com", Type: "A", Value: "93.184.216.34"}
+	mail := rapiddns.Record{Subdomain: "mail.example.com", Type: "A", Value: "93.184.216.35"}
+	serverErr := &rapiddns.APIError{HTTPStatus: 502}
+
+	for _, tt := range []struct {
+		name    string
+		results []any
+		records int
+		want    error // nil for success
+		code    int
+	}{
+		{"complete", []any{www, mail}, 2, nil, 0},
+		{"no results", nil, 0, errNoResults, exitNoResults},
+		{"failed page", []any{www, &rapiddns.PageError{Page: 2, Err: serverErr}, mail}, 2, errPartialResults, exitPartial},
+		{"failed error without page", []any{www, serverErr}, 1, errPartialResults, exitPartial},
+		{"every page failed", []any{&rapiddns.PageError{Page: 1, Err: serverErr}}, 0, rapiddns.ErrServer, exitServerError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := collectRecords(context.Background(), pages(tt.results...), false, 100)
+			if len(data.Data) != tt.records || data.Total != tt.records {
+				t.Errorf("got %d records, total %d, want %d", len(data.Data), data.Total, tt.records)
+			}
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("collectRecords() = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("collectRecords() = %v, want %v", err, tt.want)
+			}
+			if got := exitCode(err); got != tt.code {
+				t.Errorf("exitCode() = %d, want %d", got, tt.code)
+			}
+			if isResultErr(err) != (tt.records > 0 || tt.want == errNoResults) {
+				t.Errorf("isResultErr() = %v, results written must match", isResultErr(err))
+			}
+		})
+	}
+}
+
+func TestCollectRecordsInterrupted(t *testing.T) {
+	www := rapiddns.Record{Subdomain: "www.example.com", Type: "A", Value: "93.184.216.34"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	data, err := collectRecords(ctx, pages(www, context.Canceled), false, 100)
+	if len(data.Data) != 1 || !errors.Is(err, errPartialResults) || !errors.Is(err, context.Canceled) {
+		t.Errorf("collectRecords() = %d records, %v, want the record kept as partial results", len(data.Data), err)
+	}
+	if got := exitCode(err); got != exitInterrupted {
+		t.Errorf("exitCode() = %d, want %d", got, exitInterrupted)
+	}
+
+	data, err = collectRecords(ctx, pages(context.Canceled), false, 100)
+	if len(data.Data) != 0 || !errors.Is(err, context.Canceled) || isResultErr(err) {
+		t.Errorf("collectRecords() = %d records, %v, want the interruption alone", len(data.Data), err)
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rapiddns/rapiddns-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version, commit, build date and Go version",
+	Args:  exactArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		info := version.Get()
+		fmt.Printf("rapiddns-cli %s\n", info.Version)
+		fmt.Printf("Commit:      %s\n", info.Commit)
+		fmt.Printf("Built:       %s\n", info.Date)
+		fmt.Printf("Go version:  %s\n", info.GoVersion)
+		fmt.Printf("Platform:    %s\n", info.Platform)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = version.Get().Version
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const entryExt = ".json"
+
+// Cache stores raw API responses on disk, one file per request
+type Cache struct {
+	Dir string
+	TTL time.Duration // Entries older than TTL are considered expired, 0 means never
+}
+
+// entry is the on-disk representation of a cached response
+type entry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Key       string    `json:"key"`
+	Body      []byte    `json:"body"`
+}
+
+// Stats summarizes the contents of a cache directory
+type Stats struct {
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// New returns a cache rooted at dir
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+entryExt)
+}
+
+// Get returns the cached body for key if present and not expired
+func (c *Cache) Get(key string) ([]byte, bool) {
+	return c.lookup(key, false)
+}
+
+// GetStale returns the cached body for key if present, however old it is
+func (c *Cache) GetStale(key string) ([]byte, bool) {
+	return c.lookup(key, true)
+}
+
+func (c *Cache) lookup(key string, allowExpired bool) ([]byte, bool) {
+	e, err := readEntry(c.path(key))
+	if err != nil || e.Key != key || (!allowExpired && c.expired(e)) {
+		return nil, false
+	}
+	return e.Body, true
+}
+
+// Put stores body under key, replacing any previous entry
+func (c *Cache) Put(key string, body []byte) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Key: key, Body: body})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Stats reports the number and size of entries in the cache
+func (c *Cache) Stats() (Stats, error) {
+	var st Stats
+	err := c.walk(func(path string, e *entry, size int64) error {
+		st.Entries++
+		st.Bytes += size
+		if e == nil || c.expired(e) {
+			// Unreadable entries are removed by Prune as well
+			st.Expired++
+			return nil
+		}
+		if st.Oldest.IsZero() || e.CreatedAt.Before(st.Oldest) {
+			st.Oldest = e.CreatedAt
+		}
+		if e.CreatedAt.After(st.Newest) {
+			st.Newest = e.CreatedAt
+		}
+		return nil
+	})
+	return st, err
+}
+
+// Clear removes all entries and returns how many were removed
+func (c *Cache) Clear() (int, error) {
+	removed := 0
+	err := c.walk(func(path string, e *entry, size int64) error {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// Prune removes expired and unreadable entries and returns how many were removed
+func (c *Cache) Prune() (int, error) {
+	removed := 0
+	err := c.walk(func(path string, e *entry, size int64) error {
+		if e != nil && !c.expired(e) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return c.TTL > 0 && time.Since(e.CreatedAt) > c.TTL
+}
+
+// walk calls fn for every entry file in the cache directory.
+// e is nil for files that cannot be decoded.
+func (c *Cache) walk(fn func(path string, e *entry, size int64) error) error {
+	files, err := os.ReadDir(c.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != entryExt {
+			continue
+		}
+		path := filepath.Join(c.Dir, f.Name())
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		e, err := readEntry(path)
+		if err != nil {
+			e = nil
+		}
+		if err := fn(path, e, info.Size()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEntry(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
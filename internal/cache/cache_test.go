@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// putAged stores body under key as if it had been cached age ago
+func putAged(t *testing.T, c *Cache, key, body string, age time.Duration) {
+	t.Helper()
+	data, err := json.Marshal(entry{CreatedAt: time.Now().Add(-age), Key: key, Body: []byte(body)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "responses"), time.Hour)
+	if _, ok := c.Get("search\x00example.com"); ok {
+		t.Error("Get of an empty cache succeeded")
+	}
+	if err := c.Put("search\x00example.com", []byte(`{"status": 200}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("search\x00example.com", []byte(`{"status": "200"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	body, ok := c.Get("search\x00example.com")
+	if !ok || string(body) != `{"status": "200"}` {
+		t.Errorf("Get() = %q, %v, want the latest body", body, ok)
+	}
+	if _, ok := c.Get("search\x00example.org"); ok {
+		t.Error("Get of another key succeeded")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		ttl, age  time.Duration
+		wantFresh bool
+	}{
+		{"fresh", time.Hour, time.Minute, true},
+		{"expired", time.Hour, 2 * time.Hour, false},
+		{"no expiry", 0, 365 * 24 * time.Hour, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(t.TempDir(), tt.ttl)
+			putAged(t, c, "key", "body", tt.age)
+
+			if _, ok := c.Get("key"); ok != tt.wantFresh {
+				t.Errorf("Get() found %v, want %v", ok, tt.wantFresh)
+			}
+			if body, ok := c.GetStale("key"); !ok || string(body) != "body" {
+				t.Errorf("GetStale() = %q, %v, want the body however old", body, ok)
+			}
+		})
+	}
+}
+
+func TestUnreadableEntries(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+	putAged(t, c, "other", "body", 0)
+	data, err := os.ReadFile(c.path("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string][]byte{
+		"corrupt":   []byte("not json"),
+		"truncated": data[:len(data)/2],
+		"empty":     nil,
+		"wrong key": data, // a hash collision, or a file copied to the wrong name
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(c.path("key"), content, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if body, ok := c.Get("key"); ok {
+				t.Errorf("Get() = %q", body)
+			}
+			if body, ok := c.GetStale("key"); ok {
+				t.Errorf("GetStale() = %q", body)
+			}
+		})
+	}
+}
+
+func TestStatsPruneClear(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+	putAged(t, c, "fresh", "body", time.Minute)
+	putAged(t, c, "newer", "body", time.Second)
+	putAged(t, c, "expired", "body", 2*time.Hour)
+	if err := os.WriteFile(c.path("corrupt"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Files that are not entries are left alone
+	if err := os.WriteFile(filepath.Join(c.Dir, "notes.txt"), []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Entries != 4 || st.Expired != 2 || st.Bytes == 0 {
+		t.Errorf("Stats() = %+v, want 4 entries, 2 of them expired", st)
+	}
+	if !st.Oldest.Before(st.Newest) || time.Since(st.Oldest) > time.Hour {
+		t.Errorf("Stats() oldest %v, newest %v: expired entries must not count", st.Oldest, st.Newest)
+	}
+
+	if n, err := c.Prune(); err != nil || n != 2 {
+		t.Errorf("Prune() = %d, %v, want 2 removed", n, err)
+	}
+	if _, ok := c.Get("fresh"); !ok {
+		t.Error("Prune removed a fresh entry")
+	}
+
+	if n, err := c.Clear(); err != nil || n != 2 {
+		t.Errorf("Clear() = %d, %v, want 2 removed", n, err)
+	}
+	if _, ok := c.GetStale("fresh"); ok {
+		t.Error("entry left after Clear")
+	}
+	if _, err := os.Stat(filepath.Join(c.Dir, "notes.txt")); err != nil {
+		t.Errorf("Clear removed a file that is not an entry: %v", err)
+	}
+}
+
+func TestMissingDir(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "missing"), time.Hour)
+	if st, err := c.Stats(); err != nil || st.Entries != 0 {
+		t.Errorf("Stats() = %+v, %v", st, err)
+	}
+	if n, err := c.Prune(); err != nil || n != 0 {
+		t.Errorf("Prune() = %d, %v", n, err)
+	}
+	if n, err := c.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear() = %d, %v", n, err)
+	}
+}
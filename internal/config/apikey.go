@@ -0,0 +1,45 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// resolvedKey caches the key decrypted from api_key_encrypted or printed by
+// api_key_command, so that the user is asked at most once per process
+var resolvedKey struct {
+	once sync.Once
+	key  string
+	err  error
+}
+
+// runKeyCommand runs command with the system shell and returns the first line of
+// its output, like password managers such as pass print the secret. The helper
+// inherits stdin and stderr so it can prompt for a passphrase.
+func runKeyCommand(command string) (string, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	c.Stdin = os.Stdin
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("running %s: %w", APIKeyCommand, err)
+	}
+
+	key, _, _ := strings.Cut(stdout.String(), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("%s printed no API key", APIKeyCommand)
+	}
+	return key, nil
+}
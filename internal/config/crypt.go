@@ -0,0 +1,158 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// PassphraseEnv names the environment variable api_key_encrypted is unlocked with
+const PassphraseEnv = EnvPrefix + "_PASSPHRASE"
+
+// encryptedPrefix marks the format of api_key_encrypted values: the base64 of
+// salt, nonce and AES-256-GCM ciphertext, with the key derived by scrypt using
+// the parameters below. Changing any of them requires a new prefix.
+const encryptedPrefix = "scrypt-aesgcm-v1:"
+
+const (
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	saltSize  = 16
+	keyLength = 32
+)
+
+// errWrongPassphrase is returned when api_key_encrypted cannot be decrypted
+var errWrongPassphrase = errors.New("wrong passphrase or damaged " + APIKeyEncrypted)
+
+// EncryptAPIKey encrypts key with passphrase for storage in api_key_encrypted
+func EncryptAPIKey(key, passphrase string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, []byte(key), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decryptAPIKey reverses EncryptAPIKey
+func decryptAPIKey(value, passphrase string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return "", fmt.Errorf("%s has an unknown format", APIKeyEncrypted)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < saltSize {
+		return "", errWrongPassphrase
+	}
+
+	aead, err := newAEAD(passphrase, data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return "", errWrongPassphrase
+	}
+	key, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errWrongPassphrase
+	}
+	return string(key), nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Passphrase returns the passphrase from RAPIDDNS_PASSPHRASE, or asks for it on
+// the terminal without echo. With confirm it is asked for twice.
+func Passphrase(confirm bool) (string, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	p, err := ReadSecret("Passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase (or set %s): %w", PassphraseEnv, err)
+	}
+	if p == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+	if confirm {
+		again, err := ReadSecret("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return p, nil
+}
+
+// ReadSecret prints prompt to stderr and reads a line from the terminal without echo
+func ReadSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("stdin is not a terminal")
+	}
+	state, err := term.GetState(fd)
+	if err != nil {
+		return "", err
+	}
+
+	// The read cannot be cancelled, so Ctrl-C has to exit here, but not before
+	// echo is turned back on
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(interrupt)
+		close(done)
+	}()
+	go func() {
+		select {
+		case <-interrupt:
+			term.Restore(fd, state)
+			fmt.Fprintln(os.Stderr)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptAPIKeyRoundTrip(t *testing.T) {
+	const key, passphrase = "0123456789abcdef", "correct horse battery staple"
+	encrypted, err := EncryptAPIKey(key, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, key) {
+		t.Errorf("EncryptAPIKey() = %q", encrypted)
+	}
+	again, err := EncryptAPIKey(key, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encrypted {
+		t.Error("encrypting twice gave the same value, salt or nonce reused")
+	}
+
+	for _, value := range []string{encrypted, again} {
+		got, err := decryptAPIKey(value, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != key {
+			t.Errorf("decryptAPIKey() = %q, want %q", got, key)
+		}
+	}
+}
+
+func TestDecryptAPIKeyErrors(t *testing.T) {
+	const passphrase = "secret"
+	encrypted, err := EncryptAPIKey("0123456789abcdef", passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, encryptedPrefix))
+	damaged := append([]byte(nil), data...)
+	damaged[len(damaged)-1] ^= 1
+	encode := func(b []byte) string { return encryptedPrefix + base64.StdEncoding.EncodeToString(b) }
+
+	for _, tt := range []struct {
+		name, value, passphrase string
+		wrongPassphrase         bool
+	}{
+		{"wrong passphrase", encrypted, "Secret", true},
+		{"damaged ciphertext", encode(damaged), passphrase, true},
+		{"truncated", encode(data[:saltSize+4]), passphrase, true},
+		{"not base64", encryptedPrefix + "???", passphrase, true},
+		{"unknown format", "aes:" + strings.TrimPrefix(encrypted, encryptedPrefix), passphrase, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := decryptAPIKey(tt.value, tt.passphrase)
+			if err == nil {
+				t.Fatalf("decryptAPIKey() = %q, want an error", key)
+			}
+			if errors.Is(err, errWrongPassphrase) != tt.wrongPassphrase {
+				t.Errorf("decryptAPIKey() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGetAPIKeyEncrypted(t *testing.T) {
+	encrypted, err := EncryptAPIKey("0123456789abcdef", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(PassphraseEnv, "secret")
+	loadTestConfig(t, APIKeyEncrypted+": "+encrypted+"\n", "")
+
+	if src, _ := APIKeySource(); src != APIKeyEncrypted {
+		t.Errorf("APIKeySource() = %q, want %q", src, APIKeyEncrypted)
+	}
+	key, err := GetAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "0123456789abcdef" {
+		t.Errorf("GetAPIKey() = %q", key)
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// File is a YAML config file opened for editing. Keys are dotted paths such as
+// "profiles.acme.api_key". Comments and key order of the file are kept.
+type File struct {
+	Path string
+	root *yaml.Node // Top level mapping
+}
+
+// OpenFile reads the config file at path. A missing file yields an empty one.
+func OpenFile(path string) (*File, error) {
+	f := &File{Path: path, root: &yaml.Node{Kind: yaml.MappingNode}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return f, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing %s: top level is not a mapping", path)
+	}
+	f.root = doc.Content[0]
+	return f, nil
+}
+
+// Get returns the value stored under key
+func (f *File) Get(key string) (interface{}, bool) {
+	node := f.lookup(key)
+	if node == nil {
+		return nil, false
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// Has reports whether key is present
+func (f *File) Has(key string) bool {
+	return f.lookup(key) != nil
+}
+
+// Keys returns the keys of the mapping stored under key, or of the top level
+// if key is empty, in file order
+func (f *File) Keys(key string) []string {
+	node := f.root
+	if key != "" {
+		node = f.lookup(key)
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	return keys
+}
+
+// Set stores value under key, creating intermediate mappings as needed
+func (f *File) Set(key string, value interface{}) error {
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+	if valueNode.Kind == yaml.MappingNode || valueNode.Kind == yaml.SequenceNode {
+		// Empty collections encode in flow style, which would stick once filled
+		valueNode.Style = 0
+	}
+
+	parts := strings.Split(key, ".")
+	node := f.root
+	for i, part := range parts {
+		idx := mappingIndex(node, part)
+		if i == len(parts)-1 {
+			if idx >= 0 {
+				// Keep comments attached to the old value
+				valueNode.HeadComment = node.Content[idx+1].HeadComment
+				valueNode.LineComment = node.Content[idx+1].LineComment
+				node.Content[idx+1] = &valueNode
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, &valueNode)
+			}
+			return nil
+		}
+
+		if idx < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+			node = child
+			continue
+		}
+		if node.Content[idx+1].Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", strings.Join(parts[:i+1], "."))
+		}
+		node = node.Content[idx+1]
+		if len(node.Content) == 0 {
+			// An empty mapping read back from the file as {} is in flow style
+			node.Style = 0
+		}
+	}
+	return nil
+}
+
+// SetComment attaches comment, one or more lines without the leading #, to key
+func (f *File) SetComment(key, comment string) error {
+	parts := strings.Split(key, ".")
+	parent := f.root
+	if len(parts) > 1 {
+		parent = f.lookup(strings.Join(parts[:len(parts)-1], "."))
+	}
+	if parent == nil {
+		return fmt.Errorf("%s is not set", key)
+	}
+	idx := mappingIndex(parent, parts[len(parts)-1])
+	if idx < 0 {
+		return fmt.Errorf("%s is not set", key)
+	}
+	parent.Content[idx].HeadComment = "# " + strings.ReplaceAll(comment, "\n", "\n# ")
+	return nil
+}
+
+// Unset removes key and reports whether it was present
+func (f *File) Unset(key string) bool {
+	parts := strings.Split(key, ".")
+	parent := f.root
+	if len(parts) > 1 {
+		parent = f.lookup(strings.Join(parts[:len(parts)-1], "."))
+	}
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	idx := mappingIndex(parent, parts[len(parts)-1])
+	if idx < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:idx], parent.Content[idx+2:]...)
+	return true
+}
+
+// Save writes the file, readable by the owner only as it may hold API keys
+func (f *File) Save() error {
+	var buf bytes.Buffer
+	if len(f.root.Content) > 0 {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(f.root); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a failed write never truncates the config
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), ".rapiddns-*.yaml")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// lookup returns the value node stored under key
+func (f *File) lookup(key string) *yaml.Node {
+	node := f.root
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		idx := mappingIndex(node, part)
+		if idx < 0 {
+			return nil
+		}
+		node = node.Content[idx+1]
+	}
+	return node
+}
+
+// mappingIndex returns the index of the key node named key in a mapping node, or -1.
+// Keys are matched case-insensitively, like viper does.
+func mappingIndex(node *yaml.Node, key string) int {
+	if node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// openTestFile writes content to a config file in a temporary directory and opens it
+func openTestFile(t *testing.T, content string) *File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// saved saves f and returns the file content
+func saved(t *testing.T, f *File) string {
+	t.Helper()
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOpenFileMissing(t *testing.T) {
+	f, err := OpenFile(filepath.Join(t.TempDir(), "rapiddns", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := f.Keys(""); len(keys) != 0 {
+		t.Errorf("Keys() = %v, want none", keys)
+	}
+	if err := f.Set(APIKey, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved(t, f); got != "api_key: secret\n" {
+		t.Errorf("saved %q", got)
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(f.Path); info.Mode().Perm() != 0600 {
+			t.Errorf("mode = %04o, want 0600", info.Mode().Perm())
+		}
+	}
+}
+
+func TestOpenFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	for _, content := range []string{"api_key: [unclosed\n", "- a list\n"} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenFile(path); err == nil {
+			t.Errorf("OpenFile of %q succeeded", content)
+		}
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	f := openTestFile(t, `# RapidDNS settings
+api_key: old # plan key
+output: csv
+`)
+	for key, value := range map[string]interface{}{
+		APIKey:                    "new",
+		"profiles.acme.page_size": 50,
+		"Output":                  "json",
+	} {
+		if err := f.Set(key, value); err != nil {
+			t.Fatalf("Set(%s): %v", key, err)
+		}
+	}
+
+	want := `# RapidDNS settings
+api_key: new # plan key
+output: json
+profiles:
+  acme:
+    page_size: 50
+`
+	if got := saved(t, f); got != want {
+		t.Errorf("saved:\n%s\nwant:\n%s", got, want)
+	}
+	if v, ok := f.Get("PROFILES.Acme.page_size"); !ok || v != 50 {
+		t.Errorf("Get() = %v, %v, want keys matched ignoring case", v, ok)
+	}
+	if err := f.Set("output.format", "json"); err == nil {
+		t.Error("Set below a scalar succeeded")
+	}
+}
+
+func TestFileSetEmptyMapping(t *testing.T) {
+	f := openTestFile(t, "profiles:\n  acme: {}\n")
+	if err := f.Set("profiles.acme.output", "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("profiles.work", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("profiles.work.output", "csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `profiles:
+  acme:
+    output: json
+  work:
+    output: csv
+`
+	if got := saved(t, f); got != want {
+		t.Errorf("saved:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileUnset(t *testing.T) {
+	f := openTestFile(t, `api_key: secret
+profile: acme
+profiles:
+  acme:
+    output: json
+`)
+	for _, tt := range []struct {
+		key  string
+		want bool
+	}{
+		{APIKey, true},
+		{APIKey, false},
+		{"profiles.acme.output", true},
+		{"profiles.work.output", false},
+		{"profile.name", false},
+		{"PROFILE", true},
+	} {
+		if got := f.Unset(tt.key); got != tt.want {
+			t.Errorf("Unset(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	if got, want := saved(t, f), "profiles:\n  acme: {}\n"; got != want {
+		t.Errorf("saved %q, want %q", got, want)
+	}
+}
+
+func TestFileSetComment(t *testing.T) {
+	f := openTestFile(t, "profiles:\n  acme:\n    output: json\n")
+	if err := f.SetComment("profiles.acme.output", "Output format\nof results"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetComment("profiles.acme.page_size", "Page size"); err == nil {
+		t.Error("SetComment of a missing key succeeded")
+	}
+
+	want := `profiles:
+  acme:
+    # Output format
+    # of results
+    output: json
+`
+	if got := saved(t, f); got != want {
+		t.Errorf("saved:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFileKeys(t *testing.T) {
+	f := openTestFile(t, `profiles:
+  work: {}
+  acme:
+    output: json
+    api_key: secret
+`)
+	if got := f.Keys("profiles"); len(got) != 2 || got[0] != "work" || got[1] != "acme" {
+		t.Errorf("Keys(profiles) = %v, want file order", got)
+	}
+	if got := f.Keys("profiles.acme"); len(got) != 2 || got[0] != "output" {
+		t.Errorf("Keys(profiles.acme) = %v", got)
+	}
+	if got := f.Keys("profiles.acme.output"); got != nil {
+		t.Errorf("Keys of a scalar = %v, want nil", got)
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Profile names are lower case only: viper lower cases every key it reads, so
+// the config file cannot tell "Acme" from "acme" anyway
+var validProfileName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+var (
+	// ErrUnknownProfile is returned for a profile the config file does not define
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrProfileExists is returned when adding a profile that is already defined
+	ErrProfileExists = errors.New("profile already exists")
+	// ErrInvalidProfileName is returned for a name ValidateProfileName rejects
+	ErrInvalidProfileName = errors.New("invalid profile name")
+)
+
+// ActiveProfile returns the profile selected with --profile, RAPIDDNS_PROFILE or
+// "profile" in the config file, or an empty string if none is. The name is lower
+// cased like the profile names read from the config file.
+func ActiveProfile() string {
+	return strings.ToLower(viper.GetString(Profile))
+}
+
+// ProfileNames returns the names of all profiles in the config file, sorted
+func ProfileNames() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap(Profiles) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileExists reports whether the config file defines the profile name
+func ProfileExists(name string) bool {
+	return viper.IsSet(Profiles + "." + name)
+}
+
+// applyProfile merges the settings of the active profile over the top level
+// settings of the config file. Flags and environment variables still win.
+func applyProfile() error {
+	name := ActiveProfile()
+	if name == "" {
+		return nil
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("%w %q, see: rapiddns config profile list", ErrUnknownProfile, name)
+	}
+	return viper.MergeConfigMap(viper.GetStringMap(Profiles + "." + name))
+}
+
+// AddProfile creates the profile name with the given settings
+func AddProfile(name string, settings map[string]interface{}) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	return Edit(func(f *File) error {
+		if f.Has(profileKey(name, "")) {
+			return fmt.Errorf("%w: %q", ErrProfileExists, name)
+		}
+		if err := f.Set(profileKey(name, ""), map[string]interface{}{}); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(settings))
+		for key := range settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := f.Set(profileKey(name, key), settings[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !validProfileName.MatchString(name) {
+		return fmt.Errorf("%w %q (use lower case letters, digits, - and _)", ErrInvalidProfileName, name)
+	}
+	return nil
+}
+
+// UseProfile makes name the profile used by default
+func UseProfile(name string) error {
+	return Edit(func(f *File) error {
+		if !f.Has(profileKey(name, "")) {
+			return fmt.Errorf("%w %q", ErrUnknownProfile, name)
+		}
+		return f.Set(Profile, strings.ToLower(name))
+	})
+}
+
+// RemoveProfile deletes the profile name. If it was the default profile, the
+// top level settings become the default again.
+func RemoveProfile(name string) error {
+	return Edit(func(f *File) error {
+		if !f.Unset(profileKey(name, "")) {
+			return fmt.Errorf("%w %q", ErrUnknownProfile, name)
+		}
+		if current, ok := f.Get(Profile); ok && strings.EqualFold(fmt.Sprint(current), name) {
+			f.Unset(Profile)
+		}
+		return nil
+	})
+}
+
+// profileKey returns the config file key of setting key in profile name. Without
+// a profile it is the top level key; without a key it is the profile itself.
+func profileKey(name, key string) string {
+	switch {
+	case name == "":
+		return key
+	case key == "":
+		return Profiles + "." + name
+	}
+	return Profiles + "." + name + "." + key
+}
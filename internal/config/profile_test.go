@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// loadTestConfig loads user as the user config file and, if local is not empty,
+// local as the project config file of the working directory. The global state
+// InitConfig fills in is reset before and after the test.
+func loadTestConfig(t *testing.T, user, local string) {
+	t.Helper()
+	reset := func() {
+		viper.Reset()
+		loadErr, userFile, localFile = nil, "", ""
+		resolvedKey.once, resolvedKey.key, resolvedKey.err = sync.Once{}, "", nil
+	}
+	reset()
+	t.Cleanup(reset)
+
+	// The project directory is below home, where the search for it stops
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := filepath.Join(home, "project")
+	if err := os.Mkdir(project, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	path := filepath.Join(home, "config.yaml")
+	if err := os.WriteFile(path, []byte(user), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if local != "" {
+		if err := os.WriteFile(filepath.Join(project, fileName), []byte(local), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	InitConfig(path)
+}
+
+func TestValidateProfileName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"acme":       true,
+		"work-2":     true,
+		"my_profile": true,
+		"Acme":       false,
+		"ACME":       false,
+		"":           false,
+		"acme.prod":  false,
+		"acme prod":  false,
+	} {
+		if err := ValidateProfileName(name); (err == nil) != valid || err != nil && !errors.Is(err, ErrInvalidProfileName) {
+			t.Errorf("ValidateProfileName(%q) = %v, want valid %v", name, err, valid)
+		}
+	}
+}
+
+const profilesConfig = `output: csv
+result_dir: results
+profile: acme
+profiles:
+  acme:
+    output: json
+  work:
+    result_dir: work-results
+`
+
+func TestApplyProfile(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		env                  string
+		profile, output, dir string
+	}{
+		{"default profile", "", "acme", "json", "results"},
+		{"selected profile", "work", "work", "csv", "work-results"},
+		{"selected ignoring case", "WORK", "work", "csv", "work-results"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPrefix+"_PROFILE", tt.env)
+			loadTestConfig(t, profilesConfig, "")
+			if err := Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ActiveProfile(); got != tt.profile {
+				t.Errorf("ActiveProfile() = %q, want %q", got, tt.profile)
+			}
+			if !slices.Contains(ProfileNames(), ActiveProfile()) {
+				t.Errorf("ProfileNames() = %v, missing the active profile", ProfileNames())
+			}
+			if got := viper.GetString("output"); got != tt.output {
+				t.Errorf("output = %q, want %q", got, tt.output)
+			}
+			if got := GetResultDir(); got != tt.dir {
+				t.Errorf("result_dir = %q, want %q", got, tt.dir)
+			}
+		})
+	}
+}
+
+func TestApplyProfileUnknown(t *testing.T) {
+	t.Setenv(EnvPrefix+"_PROFILE", "missing")
+	loadTestConfig(t, profilesConfig, "")
+	if err := Err(); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Err() = %v, want ErrUnknownProfile", err)
+	}
+}
+
+func TestProfileNamesInUpperCase(t *testing.T) {
+	// Files written by hand may use upper case, viper lower cases the names
+	loadTestConfig(t, "profile: Acme\nprofiles:\n  Acme:\n    output: json\n", "")
+	if err := Err(); err != nil {
+		t.Fatal(err)
+	}
+	if names := ProfileNames(); len(names) != 1 || names[0] != ActiveProfile() {
+		t.Errorf("ProfileNames() = %v, ActiveProfile() = %q", names, ActiveProfile())
+	}
+}
+
+func TestProfileCommands(t *testing.T) {
+	loadTestConfig(t, profilesConfig, "")
+	if err := AddProfile("test", map[string]interface{}{"output": "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddProfile("work", nil); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("adding an existing profile: %v, want ErrProfileExists", err)
+	}
+	if err := UseProfile("TEST"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveProfile("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveProfile("Test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseProfile("acme"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("using a removed profile: %v, want ErrUnknownProfile", err)
+	}
+
+	path, _ := FilePath()
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Has(Profile) {
+		t.Error("removing the default profile kept it the default")
+	}
+	if got := f.Keys(Profiles); len(got) != 1 || got[0] != "work" {
+		t.Errorf("profiles = %v, want [work]", got)
+	}
+}
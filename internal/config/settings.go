@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rapiddns/rapiddns-cli/pkg/rapiddns"
+	"github.com/spf13/viper"
+)
+
+// Keys of the command defaults that can be set in the config file
+const (
+	Output      = "output"
+	PageSize    = "pagesize"
+	Max         = "max"
+	Concurrency = "concurrency"
+	ExportMax   = "export_max"
+)
+
+// Kind is the type of a setting's value
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindDuration
+	KindURL
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindInt:
+		return "integer"
+	case KindFloat:
+		return "number"
+	case KindBool:
+		return "boolean"
+	case KindDuration:
+		return "duration"
+	case KindURL:
+		return "URL"
+	}
+	return "string"
+}
+
+// Setting describes a key that can be changed with rapiddns config set
+type Setting struct {
+	Key         string
+	Kind        Kind
+	Default     string // Shown when the setting is not set
+	Description string
+	Min         *float64 // Lower bound of Int and Float settings
+	Choices     []string // Allowed values of String settings, if restricted
+	Secret      bool     // Masked when listed
+}
+
+func atLeast(n float64) *float64 { return &n }
+
+// Settings lists every setting in the order they are shown by config list
+var Settings = []Setting{
+	{Key: APIKey, Kind: KindString, Description: "API key sent with every request", Secret: true},
+	{Key: APIKeyEncrypted, Kind: KindString, Description: "API key encrypted with a passphrase by config set-key --encrypt", Secret: true},
+	{Key: APIKeyCommand, Kind: KindString, Description: "Command printing the API key, used if api_key is not set"},
+	{Key: BaseURL, Kind: KindURL, Default: rapiddns.BaseURL, Description: "API endpoint"},
+	{Key: Proxy, Kind: KindURL, Description: "HTTP(S) or SOCKS5 proxy URL"},
+	{Key: CACert, Kind: KindString, Description: "PEM CA bundle trusted in addition to the system roots"},
+	{Key: ClientCert, Kind: KindString, Description: "PEM client certificate for mutual TLS"},
+	{Key: ClientKey, Kind: KindString, Description: "PEM private key of the client certificate"},
+	{Key: InsecureTLS, Kind: KindBool, Default: "false", Description: "Skip TLS certificate verification"},
+	{Key: RequestsPerSecond, Kind: KindFloat, Default: "0", Min: atLeast(0), Description: "Client side request rate limit, 0 for none"},
+	{Key: RequestsPerDay, Kind: KindInt, Default: "0", Min: atLeast(0), Description: "Client side daily request limit, 0 for none"},
+	{Key: CacheTTL, Kind: KindDuration, Default: DefaultCacheTTL.String(), Description: "How long cached responses are reused, 0 to keep them until cache clear"},
+	{Key: CacheDirKey, Kind: KindString, Description: "Directory responses are cached in"},
+	{Key: ResultDir, Kind: KindString, Default: DefaultResultDir, Description: "Directory result files are written to"},
+	{Key: Output, Kind: KindString, Default: "json", Choices: []string{"json", "csv", "text"}, Description: "Default output format of search"},
+	{Key: PageSize, Kind: KindInt, Default: "100", Min: atLeast(1), Description: "Default page size of search and query"},
+	{Key: Max, Kind: KindInt, Default: "10000", Min: atLeast(1), Description: "Default maximum number of records of search and query"},
+	{Key: Concurrency, Kind: KindInt, Default: "1", Min: atLeast(1), Description: "Default number of pages fetched in parallel"},
+	{Key: ExportMax, Kind: KindInt, Default: "0", Min: atLeast(0), Description: "Default maximum number of records of export start, 0 for all"},
+}
+
+// LookupSetting returns the setting named key
+func LookupSetting(key string) (Setting, bool) {
+	key = strings.ToLower(key)
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Parse validates value and converts it to the type stored in the config file
+func (s Setting) Parse(value string) (interface{}, error) {
+	switch s.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", s.Key)
+		}
+		if s.Min != nil && float64(n) < *s.Min {
+			return nil, fmt.Errorf("%s must be at least %g", s.Key, *s.Min)
+		}
+		return n, nil
+	case KindFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", s.Key)
+		}
+		if s.Min != nil && f < *s.Min {
+			return nil, fmt.Errorf("%s must be at least %g", s.Key, *s.Min)
+		}
+		return f, nil
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", s.Key)
+		}
+		return b, nil
+	case KindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s must be a duration such as 30m or 24h", s.Key)
+		}
+		return d.String(), nil
+	case KindURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%s must be an absolute URL such as https://host:port", s.Key)
+		}
+		return value, nil
+	}
+
+	if len(s.Choices) > 0 {
+		for _, c := range s.Choices {
+			if strings.EqualFold(value, c) {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be one of %s", s.Key, strings.Join(s.Choices, ", "))
+	}
+	return value, nil
+}
+
+// Value returns the effective value of the setting and whether it is set in
+// the config file, the environment or a flag
+func (s Setting) Value() (string, bool) {
+	if !viper.IsSet(s.Key) {
+		return s.Default, false
+	}
+	return viper.GetString(s.Key), true
+}
+
+// Set stores value, as returned by Setting.Parse, under key in the config file,
+// in the active profile if there is one
+func Set(key string, value interface{}) error {
+	return Edit(func(f *File) error {
+		return f.Set(profileKey(ActiveProfile(), key), value)
+	})
+}
+
+// Unset removes key from the config file, from the active profile if there is
+// one, and reports whether it was set
+func Unset(key string) (bool, error) {
+	var removed bool
+	err := Edit(func(f *File) error {
+		removed = f.Unset(profileKey(ActiveProfile(), key))
+		return nil
+	})
+	return removed, err
+}
+
+// WriteSettings stores values, as returned by Setting.Parse, in the config file,
+// in profile if it is not empty, each commented with the description of its
+// setting. Storing one form of the API key removes the other.
+func WriteSettings(profile string, values map[string]interface{}) error {
+	return Edit(func(f *File) error {
+		if _, ok := values[APIKey]; ok {
+			f.Unset(profileKey(profile, APIKeyEncrypted))
+		}
+		if _, ok := values[APIKeyEncrypted]; ok {
+			f.Unset(profileKey(profile, APIKey))
+		}
+		for _, s := range Settings {
+			value, ok := values[s.Key]
+			if !ok {
+				continue
+			}
+			key := profileKey(profile, s.Key)
+			if err := f.Set(key, value); err != nil {
+				return err
+			}
+			if err := f.SetComment(key, s.Description); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
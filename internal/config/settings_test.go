@@ -0,0 +1,77 @@
+package config
+
+import "testing"
+
+func TestSettingParse(t *testing.T) {
+	for _, tt := range []struct {
+		key, value string
+		want       interface{} // nil if value is rejected
+	}{
+		{PageSize, "50", 50},
+		{PageSize, "1", 1},
+		{PageSize, "0", nil},
+		{PageSize, "-5", nil},
+		{PageSize, "1.5", nil},
+		{PageSize, "many", nil},
+		{ExportMax, "0", 0},
+		{RequestsPerDay, "-1", nil},
+		{RequestsPerSecond, "0.5", 0.5},
+		{RequestsPerSecond, "0", 0.0},
+		{RequestsPerSecond, "-0.1", nil},
+		{RequestsPerSecond, "fast", nil},
+		{CacheTTL, "30m", "30m0s"},
+		{CacheTTL, "24h", "24h0m0s"},
+		{CacheTTL, "0", "0s"},
+		{CacheTTL, "-1h", nil},
+		{CacheTTL, "30", nil},
+		{CacheTTL, "a day", nil},
+		{BaseURL, "https://api.example.com", "https://api.example.com"},
+		{BaseURL, "http://127.0.0.1:8080/v1", "http://127.0.0.1:8080/v1"},
+		{BaseURL, "api.example.com", nil},
+		{BaseURL, "/api", nil},
+		{BaseURL, "https://", nil},
+		{BaseURL, "http://[::1", nil},
+		{Proxy, "socks5://127.0.0.1:1080", "socks5://127.0.0.1:1080"},
+		{InsecureTLS, "true", true},
+		{InsecureTLS, "0", false},
+		{InsecureTLS, "yes", nil},
+		{Output, "CSV", "csv"},
+		{Output, "text", "text"},
+		{Output, "xml", nil},
+		{ResultDir, "results/acme", "results/acme"},
+	} {
+		s, ok := LookupSetting(tt.key)
+		if !ok {
+			t.Fatalf("LookupSetting(%q) found nothing", tt.key)
+		}
+		got, err := s.Parse(tt.value)
+		switch {
+		case tt.want == nil && err == nil:
+			t.Errorf("%s: Parse(%q) = %v, want an error", tt.key, tt.value, got)
+		case tt.want != nil && err != nil:
+			t.Errorf("%s: Parse(%q) failed: %v", tt.key, tt.value, err)
+		case tt.want != nil && got != tt.want:
+			t.Errorf("%s: Parse(%q) = %#v, want %#v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSettingDefaultsParse(t *testing.T) {
+	for _, s := range Settings {
+		if s.Default == "" {
+			continue
+		}
+		if _, err := s.Parse(s.Default); err != nil {
+			t.Errorf("default of %s does not parse: %v", s.Key, err)
+		}
+	}
+}
+
+func TestLookupSetting(t *testing.T) {
+	if s, ok := LookupSetting("Cache_TTL"); !ok || s.Key != CacheTTL {
+		t.Errorf("LookupSetting(%q) = %q, %v", "Cache_TTL", s.Key, ok)
+	}
+	if _, ok := LookupSetting("profiles"); ok {
+		t.Error("LookupSetting found a key that is not a setting")
+	}
+}
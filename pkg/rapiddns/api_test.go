@@ -0,0 +1,156 @@
+package rapiddns
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update-api", false, "rewrite testdata/api.txt with the current exported API")
+
+// exportedAPI lists the exported declarations of the package, one per line:
+// functions and methods with their signatures less parameter names, each exported struct field and
+// interface method, constants, variables and types.
+func exportedAPI(t *testing.T) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	format := func(node any) string {
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, node); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(strings.Fields(buf.String()), " ")
+	}
+
+	var api []string
+	for _, file := range pkgs["rapiddns"].Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !decl.Name.IsExported() || decl.Recv != nil && !ast.IsExported(receiverName(decl.Recv)) {
+					continue
+				}
+				line := "func "
+				if decl.Recv != nil {
+					line += "(" + format(decl.Recv.List[0].Type) + ") "
+				}
+				api = append(api, line+decl.Name.Name+format(unnamed(decl.Type))[len("func"):])
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							if name.IsExported() {
+								api = append(api, decl.Tok.String()+" "+name.Name)
+							}
+						}
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() {
+							continue
+						}
+						api = append(api, typeAPI(spec, format)...)
+					}
+				}
+			}
+		}
+	}
+	slices.Sort(api)
+	return api
+}
+
+// typeAPI lists a type and, for structs and interfaces, its exported members
+func typeAPI(spec *ast.TypeSpec, format func(any) string) []string {
+	prefix := "type " + spec.Name.Name
+	var fields *ast.FieldList
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		fields = typ.Fields
+		prefix += " struct"
+	case *ast.InterfaceType:
+		fields = typ.Methods
+		prefix += " interface"
+	default:
+		return []string{prefix + " " + format(spec.Type)}
+	}
+
+	api := []string{prefix}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			if name.IsExported() {
+				typ := field.Type
+				if fn, ok := typ.(*ast.FuncType); ok {
+					typ = unnamed(fn)
+				}
+				api = append(api, prefix+", "+name.Name+" "+format(typ))
+			}
+		}
+	}
+	return api
+}
+
+// unnamed drops the parameter names of a function type, which callers never see
+func unnamed(fn *ast.FuncType) *ast.FuncType {
+	strip := func(fields *ast.FieldList) *ast.FieldList {
+		if fields == nil {
+			return nil
+		}
+		var list []*ast.Field
+		for _, field := range fields.List {
+			for range max(len(field.Names), 1) {
+				list = append(list, &ast.Field{Type: field.Type})
+			}
+		}
+		return &ast.FieldList{List: list}
+	}
+	return &ast.FuncType{TypeParams: fn.TypeParams, Params: strip(fn.Params), Results: strip(fn.Results)}
+}
+
+func receiverName(recv *ast.FieldList) string {
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// TestAPICompatibility checks the compatibility promise of the package
+// documentation: every declaration in testdata/api.txt must still exist with
+// the same signature. Additions are fine; record them with -update-api.
+func TestAPICompatibility(t *testing.T) {
+	golden := filepath.Join("testdata", "api.txt")
+	api := exportedAPI(t)
+	if *updateAPI {
+		if err := os.WriteFile(golden, []byte(strings.Join(api, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if _, found := slices.BinarySearch(api, line); !found {
+			t.Errorf("exported API changed incompatibly, missing: %s", line)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package rapiddns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// BaseURL is the default API endpoint, see WithBaseURL
+	BaseURL = "https://rapiddns.io/api"
+)
+
+// Client talks to the RapidDNS API. Create it with NewClient; a Client is safe
+// for concurrent use.
+type Client struct {
+	restyClient *resty.Client
+	apiKey      string
+	logger      *slog.Logger
+	timeout     time.Duration
+	retry       RetryPolicy
+	retries     retryCounter
+	limiter     *rateLimiter
+	perDay      int
+	usage       *Usage
+	strict      bool
+	cache       Cache
+	refresh     bool
+	offline     bool
+	tracer      *tracer
+}
+
+// NewClient creates a client configured by opts. Without options it talks to
+// BaseURL anonymously using DefaultRetryPolicy.
+func NewClient(opts ...Option) *Client {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Client{
+		apiKey:  o.apiKey,
+		logger:  o.logger,
+		timeout: o.timeout,
+		retry:   DefaultRetryPolicy,
+		perDay:  o.perDay,
+		usage:   o.usage,
+		strict:  o.strict,
+		cache:   o.cache,
+		refresh: o.refresh,
+		offline: o.offline,
+		tracer:  newTracer(o.trace),
+	}
+	if c.logger == nil {
+		c.logger = slog.New(slog.DiscardHandler)
+	}
+	if o.retry != nil {
+		c.retry = *o.retry
+	}
+	if o.perSecond > 0 {
+		c.limiter = newRateLimiter(o.perSecond)
+	}
+
+	if o.httpClient != nil {
+		c.restyClient = resty.NewWithClient(c.ownHTTPClient(o))
+	} else {
+		c.restyClient = resty.New()
+	}
+	c.restyClient.SetBaseURL(BaseURL)
+	if o.baseURL != "" {
+		c.restyClient.SetBaseURL(strings.TrimRight(o.baseURL, "/"))
+	}
+	if o.userAgent != "" {
+		c.restyClient.SetHeader("User-Agent", o.userAgent)
+	}
+	if _, err := c.restyClient.Transport(); err != nil {
+		// A custom RoundTripper, ownHTTPClient has warned about it
+		return c
+	}
+	if o.proxy != nil {
+		c.restyClient.SetProxy(o.proxy.String())
+	}
+	if o.tlsConfig != nil {
+		c.restyClient.SetTLSClientConfig(o.tlsConfig)
+	}
+	return c
+}
+
+// ownHTTPClient returns a copy of the client set with WithHTTPClient whose
+// transport the proxy and TLS options may modify. The caller's client and
+// transport, which may be http.DefaultTransport, are left alone.
+func (c *Client) ownHTTPClient(o options) *http.Client {
+	hc := *o.httpClient
+	if o.proxy == nil && o.tlsConfig == nil {
+		return &hc
+	}
+	switch t := hc.Transport.(type) {
+	case nil:
+		// resty creates a transport of its own
+	case *http.Transport:
+		hc.Transport = t.Clone()
+	default:
+		c.logger.Warn("proxy and TLS options ignored, the HTTP client has a custom transport",
+			"transport", fmt.Sprintf("%T", t))
+	}
+	return &hc
+}
+
+// Usage returns the counters set with WithUsage, or nil
+func (c *Client) Usage() *Usage {
+	return c.usage
+}
+
+// Retries returns the number of retries performed by this client so far
+func (c *Client) Retries() int {
+	return int(c.retries.n.Load())
+}
+
+// withDeadline derives the context used for a single API call
+func (c *Client) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (c *Client) getAuthHeader() map[string]string {
+	if c.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"X-API-KEY": c.apiKey}
+}
+
+// DownloadFile downloads a file from url to destPath
+func (c *Client) DownloadFile(ctx context.Context, url string, destPath string) error {
+	resp, err := c.execute(ctx, resty.MethodGet, url, func() *resty.Request {
+		return c.restyClient.R().SetOutput(destPath)
+	})
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return newHTTPError(resp)
+	}
+	return nil
+}
+
+// Search performs a keyword search
+func (c *Client) Search(ctx context.Context, keyword string, page, pageSize int, searchType string) (*Response, *SearchData, error) {
+	key, index := c.cacheKeys("search", keyword, searchType, strconv.Itoa(page), strconv.Itoa(pageSize))
+	if r, data, ok := c.fromCache(key, index); ok {
+		return r, data, nil
+	} else if c.offline {
+		return nil, nil, fmt.Errorf("%w for search %q page %d", ErrNotCached, keyword, page)
+	}
+
+	resp, err := c.execute(ctx, resty.MethodGet, "/search/"+keyword, func() *resty.Request {
+		req := c.restyClient.R().
+			SetHeaders(c.getAuthHeader()).
+			SetQueryParam("page", strconv.Itoa(page)).
+			SetQueryParam("pagesize", strconv.Itoa(pageSize))
+
+		if searchType != "" {
+			req.SetQueryParam("search_type", searchType)
+		}
+		return req
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.decodeSearchResponse(resp, key, index)
+}
+
+// AdvancedQuery performs an advanced query search
+func (c *Client) AdvancedQuery(ctx context.Context, query string, page, pageSize int) (*Response, *SearchData, error) {
+	key, index := c.cacheKeys("query", query, strconv.Itoa(page), strconv.Itoa(pageSize))
+	if r, data, ok := c.fromCache(key, index); ok {
+		return r, data, nil
+	} else if c.offline {
+		return nil, nil, fmt.Errorf("%w for query %q page %d", ErrNotCached, query, page)
+	}
+
+	resp, err := c.execute(ctx, resty.MethodGet, "/search/query/"+query, func() *resty.Request {
+		return c.restyClient.R().
+			SetHeaders(c.getAuthHeader()).
+			SetQueryParam("page", strconv.Itoa(page)).
+			SetQueryParam("pagesize", strconv.Itoa(pageSize))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.decodeSearchResponse(resp, key, index)
+}
+
+// decodeSearchResponse decodes a search or query response and caches it under key,
+// recording key under index, if it was successful
+func (c *Client) decodeSearchResponse(resp *resty.Response, key, index string) (*Response, *SearchData, error) {
+	if resp.IsError() {
+		return nil, nil, newHTTPError(resp)
+	}
+
+	r, searchData, err := decodeSearchBody(resp.StatusCode(), resp.Body(), c.strict)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.cache != nil {
+		// Failing to cache must not fail the request
+		err := c.cache.Put(key, resp.Body())
+		if err == nil {
+			err = c.cache.Put(index, []byte(key))
+		}
+		if err != nil {
+			c.logger.Warn("could not cache response", "error", err)
+		}
+	}
+	return r, searchData, nil
+}
+
+// fromCache returns the cached response for key, if caching is enabled and a fresh
+// entry exists. Offline, entries are used however old they are, and the latest
+// answer recorded under index stands in for a missing key.
+func (c *Client) fromCache(key, index string) (*Response, *SearchData, bool) {
+	if c.cache == nil || (c.refresh && !c.offline) {
+		return nil, nil, false
+	}
+	var body []byte
+	var ok bool
+	if c.offline {
+		body, ok = c.cache.GetStale(key)
+		if latest, found := c.cache.GetStale(index); !ok && found {
+			body, ok = c.cache.GetStale(string(latest))
+		}
+	} else {
+		body, ok = c.cache.Get(key)
+	}
+	if !ok {
+		return nil, nil, false
+	}
+	r, data, err := decodeSearchBody(200, body, c.strict)
+	if err != nil {
+		return nil, nil, false
+	}
+	return r, data, true
+}
+
+func decodeSearchBody(httpStatus int, body []byte, strict bool) (*Response, *SearchData, error) {
+	env, searchData, err := decodeEnvelope[SearchData](httpStatus, body, strict)
+	if err != nil {
+		return nil, nil, err
+	}
+	if searchData.Data == nil && searchData.Result == nil {
+		// Keep empty results serialized as [] rather than null
+		searchData.Data = []Record{}
+	}
+	return &Response{Status: env.Status, Msg: env.Msg, Data: *searchData}, searchData, nil
+}
+
+// ExportData initiates a data export task
+func (c *Client) ExportData(ctx context.Context, queryType, queryInput string, maxResults int, compress bool) (*ExportResponseData, error) {
+	body := map[string]interface{}{
+		"query_type":  queryType,
+		"query_input": queryInput,
+		"max_results": maxResults,
+		"compress":    compress,
+	}
+
+	// Docs say POST /api/export-data or GET. Usually POST for actions.
+	resp, err := c.execute(ctx, resty.MethodPost, "/export-data", func() *resty.Request {
+		return c.restyClient.R().
+			SetHeaders(c.getAuthHeader()).
+			SetBody(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, newHTTPError(resp)
+	}
+
+	_, exportData, err := decodeEnvelope[ExportResponseData](resp.StatusCode(), resp.Body(), c.strict)
+	if err != nil {
+		return nil, err
+	}
+	if exportData.ExportID == "" {
+		return nil, fmt.Errorf("%w: no export_id in response", ErrUnexpectedResponse)
+	}
+	return exportData, nil
+}
+
+// CheckExportStatus checks the status of an export task
+func (c *Client) CheckExportStatus(ctx context.Context, taskID string) (*ExportStatusData, error) {
+	resp, err := c.execute(ctx, resty.MethodGet, "/export-data/"+taskID, func() *resty.Request {
+		return c.restyClient.R().
+			SetHeaders(c.getAuthHeader())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, newHTTPError(resp)
+	}
+
+	_, statusData, err := decodeEnvelope[ExportStatusData](resp.StatusCode(), resp.Body(), c.strict)
+	if err != nil {
+		return nil, err
+	}
+	return statusData, nil
+}
@@ -0,0 +1,252 @@
+package rapiddns
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rapiddns/rapiddns-cli/internal/cache"
+)
+
+// memCache is an in-memory Cache
+type memCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func (m *memCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body, ok := m.entries[key]
+	return body, ok
+}
+
+func (m *memCache) GetStale(key string) ([]byte, bool) { return m.Get(key) }
+
+func (m *memCache) Put(key string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = map[string][]byte{}
+	}
+	m.entries[key] = body
+	return nil
+}
+
+// searchServer answers every search with one record and counts the requests
+func searchServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": 200, "msg": "ok", "data": {"total": 1, "status": "ok", "data": [{"subdomain": "www.example.com", "type": "A", "value": "93.184.216.34"}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCacheKeyedByEndpointAndAPIKey(t *testing.T) {
+	var requestsA, requestsB atomic.Int32
+	srvA, srvB := searchServer(t, &requestsA), searchServer(t, &requestsB)
+	cache := &memCache{}
+	ctx := context.Background()
+
+	search := func(opts ...Option) {
+		t.Helper()
+		c := NewClient(append(opts, WithCache(cache, false))...)
+		if _, _, err := c.Search(ctx, "example.com", 1, 100, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search(WithBaseURL(srvA.URL))
+	search(WithBaseURL(srvA.URL))
+	if n := requestsA.Load(); n != 1 {
+		t.Fatalf("server A got %d requests, want 1 (second search from cache)", n)
+	}
+
+	search(WithBaseURL(srvB.URL))
+	if n := requestsB.Load(); n != 1 {
+		t.Errorf("server B got %d requests, want 1: answer of server A reused", n)
+	}
+
+	search(WithBaseURL(srvA.URL), WithAPIKey("pro-key"))
+	search(WithBaseURL(srvA.URL), WithAPIKey("other-key"))
+	if n := requestsA.Load(); n != 3 {
+		t.Errorf("server A got %d requests, want 3: answers reused across API keys", n)
+	}
+
+	for key, body := range cache.entries {
+		if strings.Contains(key, "pro-key") || strings.Contains(string(body), "pro-key") {
+			t.Errorf("cache entry %q contains the API key", key)
+		}
+	}
+}
+
+func TestOfflineCacheIgnoresAPIKey(t *testing.T) {
+	var requests atomic.Int32
+	srv := searchServer(t, &requests)
+	cache := &memCache{}
+	ctx := context.Background()
+
+	online := NewClient(WithBaseURL(srv.URL), WithAPIKey("key-a"), WithCache(cache, false))
+	if _, _, err := online.Search(ctx, "example.com", 1, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range [][]Option{
+		{},
+		{WithAPIKey("key-b")},
+		{WithAPIKey("key-a"), WithBaseURL(srv.URL)},
+	} {
+		offline := NewClient(append(opts, WithCache(cache, false), WithOffline(true))...)
+		_, data, err := offline.Search(ctx, "example.com", 1, 100, "")
+		if err != nil {
+			t.Fatalf("offline search: %v", err)
+		}
+		if len(data.Records()) != 1 {
+			t.Errorf("offline search returned %d records, want 1", len(data.Records()))
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+
+	// Online the answer fetched with another key is not reused
+	if _, _, err := NewClient(WithBaseURL(srv.URL), WithCache(cache, false)).Search(ctx, "example.com", 1, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
+
+func TestNewClientKeepsHTTPClient(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.example.com:3128")
+	tlsConfig := &tls.Config{ServerName: "rapiddns.example.com"}
+
+	for _, tt := range []struct {
+		name      string
+		transport *http.Transport
+	}{
+		{"own transport", &http.Transport{}},
+		{"default transport", http.DefaultTransport.(*http.Transport)},
+		{"no transport", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := &http.Client{}
+			if tt.transport != nil {
+				hc.Transport = tt.transport
+			}
+			c := NewClient(WithHTTPClient(hc), WithProxy(proxy), WithTLSConfig(tlsConfig))
+
+			if tt.transport == nil {
+				if hc.Transport != nil {
+					t.Error("client of the caller modified")
+				}
+			} else if hc.Transport != tt.transport || tt.transport.TLSClientConfig == tlsConfig {
+				t.Error("transport of the caller modified")
+			}
+			if tt.transport != nil && tt.transport.Proxy != nil {
+				req, _ := http.NewRequest(http.MethodGet, BaseURL, nil)
+				if got, _ := tt.transport.Proxy(req); got != nil && got.String() == proxy.String() {
+					t.Error("proxy set on the transport of the caller")
+				}
+			}
+
+			used, err := c.restyClient.Transport()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if used.TLSClientConfig != tlsConfig {
+				t.Error("TLS config not applied")
+			}
+			req, _ := http.NewRequest(http.MethodGet, BaseURL, nil)
+			if got, _ := used.Proxy(req); got == nil || got.String() != proxy.String() {
+				t.Errorf("proxy = %v, want %v", got, proxy)
+			}
+		})
+	}
+}
+
+// roundTripper is a transport that is not an *http.Transport
+type roundTripper struct{}
+
+func (roundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestNewClientCustomRoundTripper(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.example.com:3128")
+	hc := &http.Client{Transport: roundTripper{}}
+	c := NewClient(WithHTTPClient(hc), WithProxy(proxy))
+	if _, ok := c.restyClient.GetClient().Transport.(roundTripper); !ok {
+		t.Errorf("transport = %T, want the custom one", c.restyClient.GetClient().Transport)
+	}
+}
+
+// TestOfflineCopiedCache follows the air-gapped workflow: the cache filled online
+// is copied to another directory and read offline without an API key, long after
+// the entries expired.
+func TestOfflineCopiedCache(t *testing.T) {
+	var requests atomic.Int32
+	srv := searchServer(t, &requests)
+	ctx := context.Background()
+
+	online := cache.New(t.TempDir(), time.Hour)
+	c := NewClient(WithBaseURL(srv.URL), WithAPIKey("key-a"), WithCache(online, false))
+	for _, err := range c.SearchAll(ctx, "example.com", "", PageOptions{PageSize: 100}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	copied := cache.New(t.TempDir(), time.Nanosecond)
+	files, err := os.ReadDir(online.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(online.Dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(copied.Dir, f.Name()), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(time.Millisecond)
+
+	offline := NewClient(WithCache(copied, false), WithOffline(true))
+	var records []Record
+	for record, err := range offline.SearchAll(ctx, "example.com", "", PageOptions{PageSize: 100}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 1 || records[0].Subdomain != "www.example.com" {
+		t.Errorf("offline records = %+v", records)
+	}
+
+	_, _, err = offline.Search(ctx, "example.org", 1, 100, "")
+	if !errors.Is(err, ErrNotCached) || !errors.Is(err, ErrOffline) {
+		t.Errorf("search of an uncached keyword: error = %v, want ErrNotCached", err)
+	}
+	if _, err := offline.ExportData(ctx, "subdomain", "example.com", 0, false); !errors.Is(err, ErrOffline) || errors.Is(err, ErrNotCached) {
+		t.Errorf("export offline: error = %v, want ErrOffline", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,27 @@
+// Package rapiddns is a Go client for the RapidDNS API (https://rapiddns.io/help/api).
+//
+// It provides keyword search, advanced queries with automatic pagination,
+// and the data export workflow used by the rapiddns command line tool:
+//
+//	client := rapiddns.NewClient(rapiddns.WithAPIKey(os.Getenv("RAPIDDNS_API_KEY")))
+//
+//	opts := rapiddns.PageOptions{PageSize: 100, Max: 1000}
+//	for record, err := range client.SearchAll(ctx, "example.com", "", opts) {
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		fmt.Println(record.Subdomain, record.Type, record.Value)
+//	}
+//
+// Errors returned by the client can be inspected with errors.Is against the
+// sentinel errors of this package (ErrUnauthorized, ErrRateLimited, ...) and
+// with errors.As against *APIError and *PageError.
+//
+// # Compatibility
+//
+// The exported API of this package follows semantic versioning of the
+// rapiddns-cli module: within a major version, exported identifiers are not
+// removed or renamed and function signatures do not change. New functions,
+// options, struct fields and sentinel errors may be added in minor releases.
+// The packages under internal/ and cmd/ carry no such promise.
+package rapiddns
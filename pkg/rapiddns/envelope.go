@@ -0,0 +1,129 @@
+package rapiddns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedResponse is returned when a response body does not have the expected shape
+var ErrUnexpectedResponse = errors.New("unexpected response from the API")
+
+// envelope is the wrapper every API response comes in, e.g.
+//
+//	{ "status": 200, "msg": "ok", "data": { "total": 45, "status": "ok", "data": [...] } }
+//
+// Depending on the endpoint and API version the payload is found in "message" or in "data".
+type envelope struct {
+	Status  interface{}     `json:"status"` // Can be int or string
+	Msg     string          `json:"msg"`
+	Message json.RawMessage `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// payload is implemented by the types carried inside an envelope
+type payload interface {
+	// empty reports whether a decoded value carries no information, which
+	// usually means the object was decoded from the wrong field
+	empty() bool
+}
+
+func (d *SearchData) empty() bool {
+	return len(d.Data) == 0 && len(d.Result) == 0 && d.Total == 0 && d.Status == ""
+}
+
+func (d *ExportResponseData) empty() bool {
+	return d.ExportID == ""
+}
+
+func (d *ExportStatusData) empty() bool {
+	return d.ID == "" && d.Status == ""
+}
+
+// statusOK reports whether the envelope status denotes success
+func (e *envelope) statusOK() bool {
+	switch s := e.Status.(type) {
+	case float64:
+		return s == 200
+	case string:
+		return s == "200" || s == "ok"
+	}
+	return false
+}
+
+// decodeEnvelope decodes a successful HTTP response body and extracts its payload.
+//
+// The payload is looked up in "message" first and then in "data"; the first field
+// holding a non-empty object wins. A string payload of "ok" stands for an empty
+// result, any other string is an error message from the API, reported as
+// ErrInvalidQuery. In strict mode unknown fields and payloads that fail to decode
+// are reported as ErrUnexpectedResponse instead of being skipped.
+func decodeEnvelope[T any, PT interface {
+	*T
+	payload
+}](httpStatus int, body []byte, strict bool) (*envelope, *T, error) {
+	var env envelope
+	if err := unmarshal(body, &env, strict); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+	}
+	if !env.statusOK() {
+		return nil, nil, &APIError{HTTPStatus: httpStatus, Status: statusString(env.Status), Msg: env.Msg}
+	}
+
+	var (
+		sawEmpty  bool   // a field held an object without data
+		sawOK     bool   // a field held the string "ok"
+		message   string // a field held any other string
+		decodeErr error
+	)
+	for _, raw := range []json.RawMessage{env.Message, env.Data} {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			if s == "ok" {
+				sawOK = true
+			} else if s != "" {
+				message = s
+			}
+			continue
+		}
+
+		v := new(T)
+		if err := unmarshal(raw, v, strict); err != nil {
+			if strict {
+				return nil, nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, err)
+			}
+			decodeErr = err
+			continue
+		}
+		if !PT(v).empty() {
+			return &env, v, nil
+		}
+		sawEmpty = true
+	}
+
+	switch {
+	case sawEmpty, sawOK && message == "":
+		return &env, new(T), nil
+	case message != "":
+		return nil, nil, &APIError{HTTPStatus: httpStatus, Status: statusString(env.Status), Msg: message, rejected: true}
+	case decodeErr != nil:
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnexpectedResponse, decodeErr)
+	}
+	return nil, nil, fmt.Errorf("%w: no payload in response", ErrUnexpectedResponse)
+}
+
+// unmarshal decodes data into v, rejecting unknown fields in strict mode
+func unmarshal(data []byte, v interface{}, strict bool) error {
+	if !strict {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
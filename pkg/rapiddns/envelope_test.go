@@ -0,0 +1,142 @@
+package rapiddns
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "envelope", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeEnvelopeSearch(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		strict    bool
+		wantTotal int
+		wantNames []string
+		wantErr   error
+	}{
+		{fixture: "search_data.json", wantTotal: 2, wantNames: []string{"www.example.com", "mail.example.com"}},
+		{fixture: "search_data.json", strict: true, wantTotal: 2, wantNames: []string{"www.example.com", "mail.example.com"}},
+		{fixture: "search_message.json", wantTotal: 1, wantNames: []string{"www.example.com"}},
+		{fixture: "query_result.json", wantTotal: 1, wantNames: []string{"api.apple.com"}},
+		{fixture: "search_ok.json"},
+		{fixture: "search_ok.json", strict: true},
+		{fixture: "search_empty.json"},
+		{fixture: "unknown_field.json", wantTotal: 1, wantNames: []string{"www.example.com"}},
+		{fixture: "unknown_field.json", strict: true, wantErr: ErrUnexpectedResponse},
+		{fixture: "wrong_type.json", wantErr: ErrUnexpectedResponse},
+		{fixture: "wrong_type.json", strict: true, wantErr: ErrUnexpectedResponse},
+		{fixture: "not_json.html", wantErr: ErrUnexpectedResponse},
+		{fixture: "status_unauthorized.json", wantErr: ErrUnauthorized},
+		{fixture: "status_rate_limited.json", wantErr: ErrRateLimited},
+	}
+	for _, tt := range tests {
+		name := tt.fixture
+		if tt.strict {
+			name += "/strict"
+		}
+		t.Run(name, func(t *testing.T) {
+			_, data, err := decodeEnvelope[SearchData](200, fixture(t, tt.fixture), tt.strict)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", data.Total, tt.wantTotal)
+			}
+			records := data.Records()
+			if len(records) != len(tt.wantNames) {
+				t.Fatalf("got %d records, want %d", len(records), len(tt.wantNames))
+			}
+			for i, r := range records {
+				if r.Subdomain != tt.wantNames[i] {
+					t.Errorf("record %d = %q, want %q", i, r.Subdomain, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEnvelopeStringMessage(t *testing.T) {
+	_, _, err := decodeEnvelope[SearchData](200, fixture(t, "error_message.json"), false)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if want := "Invalid query syntax near 'AND'"; apiErr.Msg != want {
+		t.Errorf("message = %q, want %q", apiErr.Msg, want)
+	}
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestDecodeEnvelopeStatus(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		wantStatus interface{}
+	}{
+		{"search_data.json", float64(200)},
+		{"query_result.json", "200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			env, _, err := decodeEnvelope[SearchData](200, fixture(t, tt.fixture), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.Status != tt.wantStatus {
+				t.Errorf("status = %#v, want %#v", env.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDecodeEnvelopeExport(t *testing.T) {
+	_, created, err := decodeEnvelope[ExportResponseData](200, fixture(t, "export_created.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ExportID != "exp_5f2c9a" {
+		t.Errorf("export_id = %q", created.ExportID)
+	}
+
+	env, status, err := decodeEnvelope[ExportStatusData](200, fixture(t, "export_status.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Status != "ok" {
+		t.Errorf("envelope status = %#v, want \"ok\"", env.Status)
+	}
+	want := ExportStatusData{ID: "exp_5f2c9a", Status: "completed", ProgressPercent: 100, DownloadURL: "https://rapiddns.io/download/exp_5f2c9a.zip"}
+	if *status != want {
+		t.Errorf("status = %+v, want %+v", *status, want)
+	}
+}
+
+func TestDecodeSearchBodyEmptyIsArray(t *testing.T) {
+	r, data, err := decodeSearchBody(200, fixture(t, "search_ok.json"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Data == nil {
+		t.Error("data is nil, want an empty slice")
+	}
+	if r.Data.(SearchData).Data == nil {
+		t.Error("response data is nil, want an empty slice")
+	}
+}
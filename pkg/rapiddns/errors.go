@@ -0,0 +1,108 @@
+package rapiddns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Sentinel errors for the classes of failure callers usually need to tell apart.
+// Use errors.Is to check for them; *APIError unwraps to the matching sentinel.
+var (
+	ErrUnauthorized  = errors.New("API key is invalid or expired")
+	ErrRateLimited   = errors.New("rate limited by the API")
+	ErrQuotaExceeded = errors.New("daily request limit reached")
+	ErrInvalidQuery  = errors.New("invalid query or parameters")
+	ErrServer        = errors.New("API server error")
+	ErrOffline       = errors.New("not available offline")
+
+	// ErrNotCached is returned offline for a search or query that is not in
+	// the cache. It matches ErrOffline as well.
+	ErrNotCached = fmt.Errorf("%w: no cached result", ErrOffline)
+)
+
+// APIError is returned when the API rejects a request, either with a non-2xx
+// HTTP status or with an error status inside the response body
+type APIError struct {
+	HTTPStatus int    // HTTP status code of the response
+	Status     string // "status" field of the response body, if any
+	Msg        string // Message returned by the API, if any
+
+	// rejected is set when the API answered with a success status but an
+	// error message in place of the payload, as it does for malformed queries
+	rejected bool
+}
+
+func (e *APIError) Error() string {
+	msg := e.Msg
+	if code := e.code(); msg == "" && code != http.StatusOK {
+		msg = fmt.Sprintf("%d %s", code, http.StatusText(code))
+	}
+	if sentinel := e.Unwrap(); sentinel != nil {
+		return fmt.Sprintf("%s: %s", sentinel, msg)
+	}
+	if msg == "" {
+		return "API error"
+	}
+	return "API error: " + msg
+}
+
+// Unwrap maps the error onto one of the package sentinel errors
+func (e *APIError) Unwrap() error {
+	code := e.code()
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrUnauthorized
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code == http.StatusBadRequest || code == http.StatusNotFound || code == http.StatusUnprocessableEntity:
+		return ErrInvalidQuery
+	case code >= 500:
+		return ErrServer
+	case e.rejected:
+		return ErrInvalidQuery
+	}
+	return nil
+}
+
+// code returns the most specific status code known: a numeric status in the
+// response body wins over the HTTP status, which is often just 200
+func (e *APIError) code() int {
+	if n, err := strconv.Atoi(e.Status); err == nil && n >= 400 {
+		return n
+	}
+	return e.HTTPStatus
+}
+
+// newHTTPError builds an *APIError from a non-2xx response
+func newHTTPError(resp *resty.Response) error {
+	apiErr := &APIError{HTTPStatus: resp.StatusCode()}
+
+	var body struct {
+		Status interface{} `json:"status"`
+		Msg    string      `json:"msg"`
+	}
+	if err := json.Unmarshal(resp.Body(), &body); err == nil {
+		apiErr.Status = statusString(body.Status)
+		apiErr.Msg = body.Msg
+	}
+	return apiErr
+}
+
+// statusString renders the polymorphic "status" field of a response body
+func statusString(status interface{}) string {
+	switch s := status.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	default:
+		return fmt.Sprint(s)
+	}
+}
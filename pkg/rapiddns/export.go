@@ -0,0 +1,52 @@
+package rapiddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExportFailed is returned by WaitExport when the server reports the export task as failed
+var ErrExportFailed = errors.New("export task failed")
+
+// Export task states reported in ExportStatusData.Status
+const (
+	ExportPending    = "pending"
+	ExportProcessing = "processing"
+	ExportCompleted  = "completed"
+	ExportFailed     = "failed"
+)
+
+// WaitExport polls the status of an export task every interval until it has completed
+// and returns the final status, which carries the download URL. progress, if not nil,
+// is called with every status received. A failed task yields ErrExportFailed.
+func (c *Client) WaitExport(ctx context.Context, taskID string, interval time.Duration, progress func(*ExportStatusData)) (*ExportStatusData, error) {
+	for {
+		status, err := c.CheckExportStatus(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(status)
+		}
+
+		switch status.Status {
+		case ExportCompleted:
+			if status.DownloadURL == "" {
+				return status, fmt.Errorf("%w: export task %s completed without a download URL", ErrUnexpectedResponse, taskID)
+			}
+			return status, nil
+		case ExportFailed:
+			return status, fmt.Errorf("%w: %s", ErrExportFailed, taskID)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
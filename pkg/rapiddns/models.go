@@ -0,0 +1,55 @@
+package rapiddns
+
+// Response is the envelope of a search or query response as returned by Search
+// and AdvancedQuery. Data holds the decoded SearchData; the same page is returned
+// separately as well, so most callers only need that.
+type Response struct {
+	Status  interface{} `json:"status"` // Can be int or string
+	Msg     string      `json:"msg"`
+	Message interface{} `json:"message"` // Actual data might be here
+	Data    interface{} `json:"data"`
+}
+
+// SearchData is a page of search or query results. Total is the number of records
+// matching overall, if the API reports it; use Records to get the records of the page.
+type SearchData struct {
+	Total  int      `json:"total"`
+	Status string   `json:"status"`
+	Data   []Record `json:"data"`
+	Result []Record `json:"result,omitempty"` // For AdvancedQuery
+}
+
+// Record is a DNS record as returned by the API. The fields hold the raw strings
+// from the response so that records marshal back to the same JSON; use DNSType,
+// Name, Addr, Target and Time for parsed values.
+type Record struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Timestamp string `json:"timestamp"`
+	Date      string `json:"date"`
+	Subdomain string `json:"subdomain"`
+}
+
+// ExportResponseData is the answer to ExportData. Pass ExportID to
+// CheckExportStatus or WaitExport to follow the export task.
+type ExportResponseData struct {
+	ExportID string `json:"export_id"`
+}
+
+// ExportStatusData is the state of an export task. Status is one of ExportPending,
+// ExportProcessing, ExportCompleted and ExportFailed; once completed the file can
+// be fetched from DownloadURL with DownloadFile.
+type ExportStatusData struct {
+	ID              string `json:"id"`
+	Status          string `json:"status"` // pending, processing, completed, failed
+	ProgressPercent int    `json:"progress_percent"`
+	DownloadURL     string `json:"download_url,omitempty"`
+}
+
+// Records returns the records of a page, whichever field the API put them in
+func (d *SearchData) Records() []Record {
+	if len(d.Data) > 0 {
+		return d.Data
+	}
+	return d.Result
+}
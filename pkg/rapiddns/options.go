@@ -0,0 +1,157 @@
+package rapiddns
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created with NewClient
+type Option func(*options)
+
+type options struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	logger     *slog.Logger
+	proxy      *url.URL
+	tlsConfig  *tls.Config
+	timeout    time.Duration
+	retry      *RetryPolicy
+	perSecond  float64
+	perDay     int
+	usage      *Usage
+	strict     bool
+	cache      Cache
+	refresh    bool
+	offline    bool
+	trace      TraceOptions
+}
+
+// Cache stores raw API responses, see WithCache
+type Cache interface {
+	// Get returns the body stored under key if it is still fresh
+	Get(key string) ([]byte, bool)
+	// GetStale returns the body stored under key however old it is
+	GetStale(key string) ([]byte, bool)
+	// Put stores body under key
+	Put(key string, body []byte) error
+}
+
+// cacheKeys builds the Cache keys of a request from its endpoint and parameters.
+// Answers differ between API servers and between plans, so the base URL and a
+// hash of the API key are part of key. index leaves both out; the entry stored
+// under it holds the key of the latest answer, so that offline the cache can be
+// used with any API key and endpoint, e.g. on another machine.
+func (c *Client) cacheKeys(endpoint string, params ...string) (key, index string) {
+	var keyHash string
+	if c.apiKey != "" {
+		sum := sha256.Sum256([]byte(c.apiKey))
+		keyHash = hex.EncodeToString(sum[:])
+	}
+	request := strings.Join(append([]string{endpoint}, params...), "\x00")
+	key = strings.Join([]string{c.restyClient.BaseURL, keyHash, request}, "\x00")
+	return key, "index\x00" + request
+}
+
+// WithAPIKey sets the key sent in the X-API-KEY header. Without it requests are anonymous.
+func WithAPIKey(key string) Option {
+	return func(o *options) { o.apiKey = key }
+}
+
+// WithBaseURL points the client at a different API endpoint, e.g. a local mock
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) { o.baseURL = baseURL }
+}
+
+// WithHTTPClient makes the client send requests through a copy of hc; hc and its
+// transport are never modified. WithProxy and WithTLSConfig win over the proxy and
+// TLS settings of hc's transport and are applied to a clone of it. They are ignored,
+// with a warning, when the transport is not an *http.Transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.httpClient = hc }
+}
+
+// WithUserAgent sets the User-Agent header of all requests
+func WithUserAgent(ua string) Option {
+	return func(o *options) { o.userAgent = ua }
+}
+
+// WithLogger sets the logger the client reports retries and other diagnostics to.
+// By default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithProxy routes all requests through an HTTP(S) or SOCKS5 proxy, see ParseProxyURL
+func WithProxy(proxy *url.URL) Option {
+	return func(o *options) { o.proxy = proxy }
+}
+
+// WithTLSConfig sets the TLS configuration used for all requests, see LoadTLSConfig
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = cfg }
+}
+
+// WithTimeout sets a deadline applied to each individual API call.
+// A zero duration disables the per-call deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) { o.retry = &p }
+}
+
+// WithRateLimit limits the client to perSecond requests per second and perDay
+// requests per calendar day. Zero disables the respective limit. The daily limit
+// is enforced against the counters set with WithUsage.
+func WithRateLimit(perSecond float64, perDay int) Option {
+	return func(o *options) {
+		o.perSecond = perSecond
+		o.perDay = perDay
+	}
+}
+
+// WithUsage sets the counters every request sent by the client is recorded in
+func WithUsage(u *Usage) Option {
+	return func(o *options) { o.usage = u }
+}
+
+// WithStrict makes the client reject responses with unknown fields or
+// payloads that cannot be decoded, instead of working around them
+func WithStrict(strict bool) Option {
+	return func(o *options) { o.strict = strict }
+}
+
+// WithCache makes Search and AdvancedQuery serve responses from c while they are
+// fresh and store successful responses in it. With refresh set, cached entries
+// are ignored but still updated.
+func WithCache(c Cache, refresh bool) Option {
+	return func(o *options) {
+		o.cache = c
+		o.refresh = refresh
+	}
+}
+
+// WithOffline stops the client from making network requests. Search and
+// AdvancedQuery are answered from the cache set with WithCache regardless of
+// the age of the entries, and of the API key and base URL they were fetched
+// with; anything else fails with ErrOffline.
+func WithOffline(offline bool) Option {
+	return func(o *options) { o.offline = offline }
+}
+
+// WithTrace logs every request attempt with its method, URL, headers, status and
+// latency, and optionally saves raw responses, see TraceOptions. The API key is
+// always redacted.
+func WithTrace(opts TraceOptions) Option {
+	return func(o *options) { o.trace = opts }
+}
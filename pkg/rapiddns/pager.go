@@ -0,0 +1,201 @@
+package rapiddns
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// PageOptions controls pagination for SearchAll and QueryAll
+type PageOptions struct {
+	Page     int // First page to fetch, starting at 1
+	PageSize int // Records requested per page
+	Max      int // Stop after this many records (0 means no limit)
+
+	// Concurrency is the number of pages fetched in parallel once the first
+	// page has revealed the total number of records. Values below 2 fetch
+	// pages one after another.
+	Concurrency int
+}
+
+// PageError reports the page a paginated fetch failed on
+type PageError struct {
+	Page int
+	Err  error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("page %d: %v", e.Page, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// SearchAll iterates over the records of a keyword search, fetching pages as needed.
+// A failed page is yielded as a *PageError. Sequential iteration stops at the first
+// failure; concurrent iteration reports each failed page and goes on with the next.
+func (c *Client) SearchAll(ctx context.Context, keyword, searchType string, opts PageOptions) iter.Seq2[Record, error] {
+	return c.paginate(ctx, opts, func(ctx context.Context, page, pageSize int) (*SearchData, error) {
+		_, data, err := c.Search(ctx, keyword, page, pageSize, searchType)
+		return data, err
+	})
+}
+
+// QueryAll iterates over the records of an advanced query, fetching pages as needed.
+// Failed pages are reported as in SearchAll.
+func (c *Client) QueryAll(ctx context.Context, query string, opts PageOptions) iter.Seq2[Record, error] {
+	return c.paginate(ctx, opts, func(ctx context.Context, page, pageSize int) (*SearchData, error) {
+		_, data, err := c.AdvancedQuery(ctx, query, page, pageSize)
+		return data, err
+	})
+}
+
+type pageFetcher func(ctx context.Context, page, pageSize int) (*SearchData, error)
+
+func (c *Client) paginate(ctx context.Context, opts PageOptions, fetch pageFetcher) iter.Seq2[Record, error] {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize < 1 {
+		opts.PageSize = 100
+	}
+
+	return func(yield func(Record, error) bool) {
+		count := 0
+		// emit yields the records of one page and reports whether to go on
+		emit := func(records []Record) bool {
+			for _, record := range records {
+				if opts.Max > 0 && count >= opts.Max {
+					return false
+				}
+				if !yield(record, nil) {
+					return false
+				}
+				count++
+			}
+			return opts.Max <= 0 || count < opts.Max
+		}
+
+		for page := opts.Page; ; page++ {
+			data, err := fetch(ctx, page, opts.PageSize)
+			if err != nil {
+				yield(Record{}, &PageError{Page: page, Err: err})
+				return
+			}
+
+			records := data.Records()
+			if !emit(records) || isLastPage(data, page, opts.PageSize, len(records)) {
+				return
+			}
+
+			if opts.Concurrency > 1 && data.Total > 0 {
+				// The total is known now, fetch the remaining pages in parallel
+				for res := range fetchPages(ctx, page+1, lastPage(data.Total, opts), opts, fetch) {
+					if res.err != nil {
+						if !yield(Record{}, &PageError{Page: res.page, Err: res.err}) {
+							return
+						}
+						continue
+					}
+					if !emit(res.records) {
+						return
+					}
+				}
+				return
+			}
+		}
+	}
+}
+
+// lastPage returns the last page needed to read total records, or opts.Max records
+// starting at opts.Page, whichever comes first
+func lastPage(total int, opts PageOptions) int {
+	last := (total + opts.PageSize - 1) / opts.PageSize
+	if opts.Max > 0 {
+		last = min(last, opts.Page-1+(opts.Max+opts.PageSize-1)/opts.PageSize)
+	}
+	return last
+}
+
+type pageResult struct {
+	page    int
+	records []Record
+	err     error
+}
+
+// fetchPages fetches pages first..last with opts.Concurrency workers and yields
+// the results in page order. Stopping the iteration cancels outstanding requests.
+func fetchPages(ctx context.Context, first, last int, opts PageOptions, fetch pageFetcher) iter.Seq[pageResult] {
+	return func(yield func(pageResult) bool) {
+		n := last - first + 1
+		if n <= 0 {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		// One buffered slot per page, so workers never block on a slow consumer
+		results := make([]chan pageResult, n)
+		for i := range results {
+			results[i] = make(chan pageResult, 1)
+		}
+
+		jobs := make(chan int)
+		go func() {
+			defer close(jobs)
+			for i := range n {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		for range min(opts.Concurrency, n) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					res := pageResult{page: first + i}
+					data, err := fetch(ctx, res.page, opts.PageSize)
+					if err != nil {
+						res.err = err
+					} else {
+						res.records = data.Records()
+					}
+					results[i] <- res
+				}
+			}()
+		}
+
+		for i := range n {
+			select {
+			case res := <-results[i]:
+				if !yield(res) {
+					return
+				}
+			case <-ctx.Done():
+				// Pages after a cancellation may never be dispatched
+				yield(pageResult{page: first + i, err: ctx.Err()})
+				return
+			}
+		}
+	}
+}
+
+// isLastPage decides whether page was the final one. The API may return exactly
+// pageSize records on the last page, so Total is used when the API reports it.
+func isLastPage(data *SearchData, page, pageSize, n int) bool {
+	if n == 0 || n < pageSize {
+		return true
+	}
+	return data.Total > 0 && page*pageSize >= data.Total
+}
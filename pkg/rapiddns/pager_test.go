@@ -0,0 +1,193 @@
+package rapiddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakePages serves total records named r<i>.example.com, pageSize at a time.
+// Pages listed in fail return an error, and useResult pages put their records
+// in Result like the query endpoint does.
+type fakePages struct {
+	total       int
+	reportTotal bool // Whether the Total field is filled in
+	fail        map[int]bool
+	useResult   bool
+	jitter      bool
+	calls       atomic.Int32
+}
+
+func (f *fakePages) fetch(ctx context.Context, page, pageSize int) (*SearchData, error) {
+	f.calls.Add(1)
+	if f.jitter {
+		time.Sleep(time.Duration(rand.N(3)) * time.Millisecond)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.fail[page] {
+		return nil, fmt.Errorf("page %d is broken", page)
+	}
+	data := &SearchData{Status: "ok"}
+	if f.reportTotal {
+		data.Total = f.total
+	}
+	var records []Record
+	for i := (page - 1) * pageSize; i < min(page*pageSize, f.total); i++ {
+		records = append(records, Record{Subdomain: fmt.Sprintf("r%d.example.com", i), Type: "A"})
+	}
+	if f.useResult {
+		data.Result = records
+	} else {
+		data.Data = records
+	}
+	return data, nil
+}
+
+// collect paginates over f, returning the record names and the pages that failed
+func collect(t *testing.T, f *fakePages, opts PageOptions) (names []string, failed []int) {
+	t.Helper()
+	for r, err := range NewClient().paginate(context.Background(), opts, f.fetch) {
+		if err != nil {
+			var pageErr *PageError
+			if !errors.As(err, &pageErr) {
+				t.Fatalf("error %v is not a *PageError", err)
+			}
+			failed = append(failed, pageErr.Page)
+			continue
+		}
+		names = append(names, r.Subdomain)
+	}
+	return names, failed
+}
+
+func wantRecords(t *testing.T, names []string, from, to int) {
+	t.Helper()
+	if len(names) != to-from {
+		t.Fatalf("got %d records, want %d", len(names), to-from)
+	}
+	for i, name := range names {
+		if want := fmt.Sprintf("r%d.example.com", from+i); name != want {
+			t.Fatalf("record %d = %s, want %s", i, name, want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		pages     *fakePages
+		opts      PageOptions
+		wantTo    int
+		wantCalls int32
+	}{
+		{"total known", &fakePages{total: 25, reportTotal: true}, PageOptions{PageSize: 10}, 25, 3},
+		{"exact multiple stops on total", &fakePages{total: 30, reportTotal: true}, PageOptions{PageSize: 10}, 30, 3},
+		{"total unknown stops on short page", &fakePages{total: 25}, PageOptions{PageSize: 10}, 25, 3},
+		{"total unknown needs an empty page", &fakePages{total: 20}, PageOptions{PageSize: 10}, 20, 3},
+		{"max inside a page", &fakePages{total: 100, reportTotal: true}, PageOptions{PageSize: 10, Max: 15}, 15, 2},
+		{"max on a page boundary", &fakePages{total: 100, reportTotal: true}, PageOptions{PageSize: 10, Max: 20}, 20, 2},
+		{"result field", &fakePages{total: 12, reportTotal: true, useResult: true}, PageOptions{PageSize: 5}, 12, 3},
+		{"concurrent", &fakePages{total: 95, reportTotal: true, jitter: true}, PageOptions{PageSize: 10, Concurrency: 4}, 95, 10},
+		{"concurrent max", &fakePages{total: 95, reportTotal: true, jitter: true}, PageOptions{PageSize: 10, Max: 42, Concurrency: 4}, 42, 5},
+		{"concurrent without total", &fakePages{total: 25}, PageOptions{PageSize: 10, Concurrency: 4}, 25, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, failed := collect(t, tt.pages, tt.opts)
+			if len(failed) > 0 {
+				t.Fatalf("pages %v failed", failed)
+			}
+			wantRecords(t, names, 0, tt.wantTo)
+			if calls := tt.pages.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("fetched %d pages, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPaginateStartPage(t *testing.T) {
+	f := &fakePages{total: 50, reportTotal: true}
+	names, _ := collect(t, f, PageOptions{Page: 3, PageSize: 10, Max: 15, Concurrency: 2})
+	wantRecords(t, names, 20, 35)
+}
+
+func TestPaginateSequentialFailureStops(t *testing.T) {
+	f := &fakePages{total: 50, reportTotal: true, fail: map[int]bool{2: true}}
+	names, failed := collect(t, f, PageOptions{PageSize: 10})
+	wantRecords(t, names, 0, 10)
+	if len(failed) != 1 || failed[0] != 2 {
+		t.Errorf("failed pages = %v, want [2]", failed)
+	}
+}
+
+func TestPaginateConcurrentFailureContinues(t *testing.T) {
+	f := &fakePages{total: 50, reportTotal: true, fail: map[int]bool{3: true}, jitter: true}
+	names, failed := collect(t, f, PageOptions{PageSize: 10, Concurrency: 3})
+	if len(failed) != 1 || failed[0] != 3 {
+		t.Errorf("failed pages = %v, want [3]", failed)
+	}
+	if len(names) != 40 {
+		t.Fatalf("got %d records, want 40", len(names))
+	}
+	wantRecords(t, names[:20], 0, 20)
+	wantRecords(t, names[20:], 30, 50)
+}
+
+func TestPaginateConsumerStopsWorkers(t *testing.T) {
+	f := &fakePages{total: 1000, reportTotal: true}
+	var canceled atomic.Int32
+	fetch := func(ctx context.Context, page, pageSize int) (*SearchData, error) {
+		if page > 3 {
+			// Slow pages only finish when the iteration is stopped
+			select {
+			case <-ctx.Done():
+				canceled.Add(1)
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
+		}
+		return f.fetch(ctx, page, pageSize)
+	}
+
+	start := time.Now()
+	n := 0
+	for _, err := range NewClient().paginate(context.Background(), PageOptions{PageSize: 10, Concurrency: 4}, fetch) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 25 {
+			break
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stopping took %s, outstanding requests were not canceled", elapsed)
+	}
+	if canceled.Load() == 0 {
+		t.Error("no outstanding request was canceled")
+	}
+}
+
+func TestLastPage(t *testing.T) {
+	tests := []struct {
+		total int
+		opts  PageOptions
+		want  int
+	}{
+		{100, PageOptions{Page: 1, PageSize: 10}, 10},
+		{101, PageOptions{Page: 1, PageSize: 10}, 11},
+		{100, PageOptions{Page: 1, PageSize: 10, Max: 25}, 3},
+		{100, PageOptions{Page: 4, PageSize: 10, Max: 25}, 6},
+		{100, PageOptions{Page: 1, PageSize: 10, Max: 500}, 10},
+	}
+	for _, tt := range tests {
+		if got := lastPage(tt.total, tt.opts); got != tt.want {
+			t.Errorf("lastPage(%d, %+v) = %d, want %d", tt.total, tt.opts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package rapiddns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const usageDayFormat = "2006-01-02"
+
+// QuotaInfo is the plan quota last reported by the API through response headers
+type QuotaInfo struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     string    `json:"reset,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Usage holds local request counters, persisted between runs
+type Usage struct {
+	Day   string     `json:"day"`   // Local date the daily counter belongs to
+	Today int        `json:"today"` // Requests sent on Day
+	Total int        `json:"total"` // Requests sent since the counters were created
+	Quota *QuotaInfo `json:"quota,omitempty"`
+
+	mu      sync.Mutex
+	pending int // Requests recorded by this process and not yet saved
+}
+
+// LoadUsage reads usage counters from path. A missing file yields empty counters.
+func LoadUsage(path string) (*Usage, error) {
+	u := &Usage{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Save writes the counters to path. Counts saved by other processes since
+// this one loaded the file are kept, so concurrent runs sharing a key add up.
+// The file is locked while it is updated and replaced atomically, and it is
+// left untouched if it cannot be read.
+func (u *Usage) Save(path string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return err
+	}
+	defer unlockFile(lock)
+
+	onDisk, err := LoadUsage(path)
+	if err != nil {
+		return fmt.Errorf("reading usage counters: %w", err)
+	}
+	today := time.Now().Format(usageDayFormat)
+	if onDisk.Day != today {
+		onDisk.Day = today
+		onDisk.Today = 0
+	}
+	if u.Day == today {
+		onDisk.Today += u.pending
+	}
+	onDisk.Total += u.pending
+	if u.Quota != nil && (onDisk.Quota == nil || u.Quota.UpdatedAt.After(onDisk.Quota.UpdatedAt)) {
+		onDisk.Quota = u.Quota
+	}
+
+	data, err := json.MarshalIndent(onDisk, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "usage-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	u.Day, u.Today, u.Total, u.Quota = onDisk.Day, onDisk.Today, onDisk.Total, onDisk.Quota
+	u.pending = 0
+	return nil
+}
+
+// RequestsToday returns the number of requests sent today
+func (u *Usage) RequestsToday() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollover()
+	return u.Today
+}
+
+// rollover resets the daily counter when the date has changed. Caller holds mu.
+func (u *Usage) rollover() {
+	today := time.Now().Format(usageDayFormat)
+	if u.Day != today {
+		u.Day = today
+		u.Today = 0
+	}
+}
+
+// recordRequest counts one request sent to the API
+func (u *Usage) recordRequest() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollover()
+	u.Today++
+	u.Total++
+	u.pending++
+}
+
+// recordQuota updates the server reported quota from response headers, if any
+func (u *Usage) recordQuota(header http.Header) {
+	limit, okLimit := headerInt(header, "X-RateLimit-Limit", "RateLimit-Limit")
+	remaining, okRemaining := headerInt(header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if !okLimit && !okRemaining {
+		return
+	}
+	reset := header.Get("X-RateLimit-Reset")
+	if reset == "" {
+		reset = header.Get("RateLimit-Reset")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.Quota = &QuotaInfo{Limit: limit, Remaining: remaining, Reset: reset, UpdatedAt: time.Now()}
+}
+
+func headerInt(header http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		if v := header.Get(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
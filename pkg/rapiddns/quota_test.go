@@ -0,0 +1,63 @@
+package rapiddns
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUsageSaveConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	const runs, requests = 8, 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := &Usage{}
+			for range requests {
+				u.recordRequest()
+			}
+			errs <- u.Save(path)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := LoadUsage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Today != runs*requests || u.Total != runs*requests {
+		t.Errorf("today = %d, total = %d, want %d", u.Today, u.Total, runs*requests)
+	}
+}
+
+func TestUsageSaveKeepsUnreadableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	damaged := []byte(`{"day": "2024-01-02", "today": 5, "tot`)
+	if err := os.WriteFile(path, damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &Usage{}
+	u.recordRequest()
+	if err := u.Save(path); err == nil {
+		t.Fatal("Save() succeeded on a damaged file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(damaged) {
+		t.Errorf("Save() overwrote the damaged file with %s", data)
+	}
+}
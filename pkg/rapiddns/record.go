@@ -0,0 +1,123 @@
+package rapiddns
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordType is the DNS type of a Record, e.g. A or CNAME
+type RecordType string
+
+// Record types returned by the API
+const (
+	TypeA     RecordType = "A"
+	TypeAAAA  RecordType = "AAAA"
+	TypeCNAME RecordType = "CNAME"
+	TypeMX    RecordType = "MX"
+	TypeNS    RecordType = "NS"
+	TypePTR   RecordType = "PTR"
+	TypeSOA   RecordType = "SOA"
+	TypeSRV   RecordType = "SRV"
+	TypeTXT   RecordType = "TXT"
+	TypeCAA   RecordType = "CAA"
+)
+
+// ParseRecordType returns the RecordType for s, ignoring case and surrounding space
+func ParseRecordType(s string) RecordType {
+	return RecordType(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+// IsAddress reports whether records of this type carry an IP address
+func (t RecordType) IsAddress() bool {
+	return t == TypeA || t == TypeAAAA
+}
+
+// IsHostname reports whether records of this type point to another host name
+func (t RecordType) IsHostname() bool {
+	switch t {
+	case TypeCNAME, TypeMX, TypeNS, TypePTR, TypeSRV:
+		return true
+	}
+	return false
+}
+
+func (t RecordType) String() string {
+	return string(t)
+}
+
+// Layouts tried in order when parsing Record.Timestamp and Record.Date
+var recordTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// DNSType returns the parsed record type
+func (r Record) DNSType() RecordType {
+	return ParseRecordType(r.Type)
+}
+
+// Name returns the record owner name as a lower case FQDN without the trailing dot
+func (r Record) Name() string {
+	return NormalizeName(r.Subdomain)
+}
+
+// Addr returns the address held in Value. ok is false if Value is not an IP address,
+// as for CNAME or TXT records. IPv4-mapped IPv6 addresses are returned as IPv4.
+func (r Record) Addr() (addr netip.Addr, ok bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(r.Value))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// Target returns the host name Value points to for CNAME, MX, NS, PTR and SRV
+// records, normalized like Name. The MX preference and SRV priority, weight and
+// port are dropped. ok is false for other record types.
+func (r Record) Target() (target string, ok bool) {
+	t := r.DNSType()
+	if !t.IsHostname() {
+		return "", false
+	}
+	fields := strings.Fields(r.Value)
+	if len(fields) == 0 {
+		return "", false
+	}
+	// MX values read "10 mx.example.com", SRV values "10 5 443 host.example.com"
+	return NormalizeName(fields[len(fields)-1]), true
+}
+
+// Time returns when the record was observed, parsed from Timestamp or, if that is
+// empty or malformed, from Date. Timestamp may be Unix seconds or a date and time;
+// values without a zone are taken as UTC. ok is false if neither field parses.
+func (r Record) Time() (t time.Time, ok bool) {
+	if t, ok := parseRecordTime(r.Timestamp); ok {
+		return t, true
+	}
+	return parseRecordTime(r.Date)
+}
+
+// NormalizeName lower cases a DNS name and strips surrounding space and the trailing dot
+func NormalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+func parseRecordTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), true
+	}
+	for _, layout := range recordTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
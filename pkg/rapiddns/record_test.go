@@ -0,0 +1,113 @@
+package rapiddns
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestParseRecordType(t *testing.T) {
+	for _, tt := range []struct {
+		in       string
+		want     RecordType
+		address  bool
+		hostname bool
+	}{
+		{"A", TypeA, true, false},
+		{" aaaa ", TypeAAAA, true, false},
+		{"cname", TypeCNAME, false, true},
+		{"MX", TypeMX, false, true},
+		{"Srv", TypeSRV, false, true},
+		{"TXT", TypeTXT, false, false},
+		{"HTTPS", RecordType("HTTPS"), false, false},
+	} {
+		got := ParseRecordType(tt.in)
+		if got != tt.want || got.IsAddress() != tt.address || got.IsHostname() != tt.hostname {
+			t.Errorf("ParseRecordType(%q) = %s (address %v, hostname %v), want %s (address %v, hostname %v)",
+				tt.in, got, got.IsAddress(), got.IsHostname(), tt.want, tt.address, tt.hostname)
+		}
+	}
+}
+
+func TestRecordName(t *testing.T) {
+	for in, want := range map[string]string{
+		"www.example.com":    "www.example.com",
+		"WWW.Example.COM.":   "www.example.com",
+		" mail.example.com ": "mail.example.com",
+		"":                   "",
+	} {
+		if got := (Record{Subdomain: in}).Name(); got != want {
+			t.Errorf("Name() of %q = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRecordAddr(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  string // empty if Value is not an address
+	}{
+		{"93.184.216.34", "93.184.216.34"},
+		{" 2606:2800:220:1:248:1893:25c8:1946 ", "2606:2800:220:1:248:1893:25c8:1946"},
+		{"::ffff:192.0.2.1", "192.0.2.1"},
+		{"example.com", ""},
+		{"10 mx.example.com", ""},
+		{"", ""},
+	} {
+		addr, ok := Record{Type: "A", Value: tt.value}.Addr()
+		if tt.want == "" {
+			if ok {
+				t.Errorf("Addr() of %q = %s, want none", tt.value, addr)
+			}
+			continue
+		}
+		if !ok || addr != netip.MustParseAddr(tt.want) {
+			t.Errorf("Addr() of %q = %s, %v, want %s", tt.value, addr, ok, tt.want)
+		}
+	}
+}
+
+func TestRecordTarget(t *testing.T) {
+	for _, tt := range []struct {
+		typ, value string
+		want       string
+		ok         bool
+	}{
+		{"CNAME", "Edge.Example.NET.", "edge.example.net", true},
+		{"MX", "10 mx1.example.com.", "mx1.example.com", true},
+		{"SRV", "10 5 443 sip.example.com", "sip.example.com", true},
+		{"NS", "ns1.example.com", "ns1.example.com", true},
+		{"ptr", "host.example.com", "host.example.com", true},
+		{"A", "93.184.216.34", "", false},
+		{"TXT", "v=spf1 -all", "", false},
+		{"CNAME", " ", "", false},
+	} {
+		got, ok := Record{Type: tt.typ, Value: tt.value}.Target()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Target() of %s %q = %q, %v, want %q, %v", tt.typ, tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRecordTime(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		timestamp, date string
+		want            time.Time // zero if neither field parses
+	}{
+		{"unix seconds", "1700000000", "", time.Unix(1700000000, 0).UTC()},
+		{"RFC 3339", "2024-03-01T12:30:00+02:00", "", time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"date and time", "2024-03-01 12:30:00", "", time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"date only", "", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"malformed timestamp falls back to date", "yesterday", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"timestamp wins over date", "2024-03-02", "2024-03-01", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"neither", "", "n/a", time.Time{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Record{Timestamp: tt.timestamp, Date: tt.date}.Time()
+			if ok != !tt.want.IsZero() || !got.Equal(tt.want) {
+				t.Errorf("Time() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package rapiddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay    time.Duration // Upper bound for a single delay, including Retry-After
+}
+
+// DefaultRetryPolicy is used by clients unless overridden with WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// retryCounter counts retries across all calls made by a client
+type retryCounter struct {
+	n atomic.Int64
+}
+
+// shouldRetry reports whether a request that produced resp/err is worth retrying.
+// Authentication failures and other 4xx responses are never retried.
+//
+// Non-idempotent requests, such as starting an export, are only retried when the
+// server cannot have acted on them: the connection was never established, or the
+// request was rate limited with a Retry-After header. Otherwise a lost response
+// could lead to a duplicate export task.
+func shouldRetry(ctx context.Context, method string, resp *resty.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if !idempotent(method) {
+		if err != nil {
+			return notSent(err)
+		}
+		return resp.StatusCode() == http.StatusTooManyRequests && resp.Header().Get("Retry-After") != ""
+	}
+	if err != nil {
+		// Network level failure or per-call deadline exceeded
+		return true
+	}
+	code := resp.StatusCode()
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// idempotent reports whether sending a request with method twice has the same
+// effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// notSent reports whether err means the request never reached the server
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// backoff returns the delay before retry number attempt (starting at 1),
+// preferring the server's Retry-After header when present
+func (p RetryPolicy) backoff(attempt int, resp *resty.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header().Get("Retry-After")); ok {
+			return min(d, p.MaxDelay)
+		}
+	}
+
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// Jitter between 50% and 100% of the computed delay
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter understands both the delay-seconds and HTTP-date forms
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// acquire waits for the rate limiter and records the request about to be sent
+func (c *Client) acquire(ctx context.Context) error {
+	if c.usage != nil && c.perDay > 0 && c.usage.RequestsToday() >= c.perDay {
+		return fmt.Errorf("%w: %d requests (requests_per_day)", ErrQuotaExceeded, c.perDay)
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if c.usage != nil {
+		c.usage.recordRequest()
+	}
+	return nil
+}
+
+// execute sends the request built by newReq, retrying according to the client's policy.
+// newReq is called once per attempt so each attempt starts from a fresh request.
+func (c *Client) execute(ctx context.Context, method, url string, newReq func() *resty.Request) (*resty.Response, error) {
+	if c.offline {
+		return nil, fmt.Errorf("%w: %s %s", ErrOffline, method, url)
+	}
+	attempts := max(c.retry.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		if err := c.acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		callCtx, cancel := c.withDeadline(ctx)
+		req := newReq().SetContext(callCtx)
+		start := time.Now()
+		resp, err := req.Execute(method, url)
+		cancel()
+		if c.tracer != nil {
+			c.tracer.traceAttempt(req, resp, err, attempt, time.Since(start))
+		}
+
+		if resp != nil && c.usage != nil {
+			c.usage.recordQuota(resp.Header())
+		}
+
+		if attempt >= attempts || !shouldRetry(ctx, method, resp, err) {
+			return resp, err
+		}
+
+		c.retries.n.Add(1)
+		delay := c.retry.backoff(attempt, resp)
+		if err != nil {
+			c.logger.Debug("retrying request", "method", method, "url", url, "attempt", attempt, "delay", delay, "error", err)
+		} else {
+			c.logger.Debug("retrying request", "method", method, "url", url, "attempt", attempt, "delay", delay, "status", resp.StatusCode())
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package rapiddns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func response(code int, header http.Header) *resty.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &resty.Response{RawResponse: &http.Response{StatusCode: code, Header: header}}
+}
+
+func TestShouldRetry(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "https://rapiddns.io/api", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	reset := &url.Error{Op: "Post", URL: "https://rapiddns.io/api", Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+	retryAfter := http.Header{"Retry-After": {"1"}}
+
+	tests := []struct {
+		name   string
+		method string
+		resp   *resty.Response
+		err    error
+		want   bool
+	}{
+		{"get ok", http.MethodGet, response(200, nil), nil, false},
+		{"get 429", http.MethodGet, response(429, nil), nil, true},
+		{"get 500", http.MethodGet, response(500, nil), nil, true},
+		{"get 503", http.MethodGet, response(503, nil), nil, true},
+		{"get 401", http.MethodGet, response(401, nil), nil, false},
+		{"get 403", http.MethodGet, response(403, nil), nil, false},
+		{"get 400", http.MethodGet, response(400, nil), nil, false},
+		{"get network error", http.MethodGet, nil, reset, true},
+		{"get deadline", http.MethodGet, nil, context.DeadlineExceeded, true},
+		{"post ok", http.MethodPost, response(200, nil), nil, false},
+		{"post 500", http.MethodPost, response(500, nil), nil, false},
+		{"post 429", http.MethodPost, response(429, nil), nil, false},
+		{"post 429 retry-after", http.MethodPost, response(429, retryAfter), nil, true},
+		{"post connection refused", http.MethodPost, nil, refused, true},
+		{"post connection reset", http.MethodPost, nil, reset, false},
+		{"post deadline", http.MethodPost, nil, context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldRetry(context.Background(), tt.method, tt.resp, tt.err); got != tt.want {
+				t.Errorf("shouldRetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if shouldRetry(ctx, http.MethodGet, response(503, nil), nil) {
+		t.Error("shouldRetry() = true after the context was canceled")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package rapiddns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// redacted replaces the value of secret headers in traces and dumps
+const redacted = "[REDACTED]"
+
+// secretHeaders are never written to a trace or dump in clear text
+var secretHeaders = []string{"X-Api-Key", "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// TraceOptions configures HTTP tracing, see WithTrace
+type TraceOptions struct {
+	Output  io.Writer // Receives one block per request attempt; nil disables the trace
+	Bodies  bool      // Include request and response bodies in the trace
+	DumpDir string    // If set, every raw response is saved to a file in this directory
+}
+
+// tracer writes HTTP traces and response dumps for a client
+type tracer struct {
+	opts TraceOptions
+	mu   sync.Mutex // Serializes writes to opts.Output
+	seq  atomic.Int64
+}
+
+func newTracer(opts TraceOptions) *tracer {
+	if opts.Output == nil && opts.DumpDir == "" {
+		return nil
+	}
+	return &tracer{opts: opts}
+}
+
+// traceAttempt records a single attempt of a request. resp and err are what
+// resty returned for req; either may be nil.
+func (t *tracer) traceAttempt(req *resty.Request, resp *resty.Response, err error, attempt int, latency time.Duration) {
+	n := t.seq.Add(1)
+	method, url, header := requestLine(req)
+
+	if t.opts.Output != nil {
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "> #%d %s %s (attempt %d)\n", n, method, url, attempt)
+		writeHeaders(&b, "> ", header)
+		if t.opts.Bodies && req.Body != nil {
+			writeBody(&b, "> ", requestBody(req.Body))
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "< #%d error after %s: %v\n", n, latency.Round(time.Millisecond), err)
+		case resp != nil:
+			fmt.Fprintf(&b, "< #%d %s in %s\n", n, resp.Status(), latency.Round(time.Millisecond))
+			writeHeaders(&b, "< ", resp.Header())
+			if t.opts.Bodies {
+				writeBody(&b, "< ", resp.Body())
+			}
+		}
+		t.mu.Lock()
+		t.opts.Output.Write(b.Bytes())
+		t.mu.Unlock()
+	}
+
+	if t.opts.DumpDir != "" && resp != nil && err == nil {
+		t.dump(n, method, url, resp)
+	}
+}
+
+// dump saves a raw response as an HTTP message for bug reports. Failures are
+// reported to the trace output only, a dump must never fail a request.
+func (t *tracer) dump(n int64, method, url string, resp *resty.Response) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s %s\n", method, url)
+	fmt.Fprintf(&b, "%s %s\n", resp.Proto(), resp.Status())
+	writeHeaders(&b, "", resp.Header())
+	b.WriteString("\n")
+	b.Write(resp.Body())
+
+	name := fmt.Sprintf("%s-%03d-%s-%s.http", time.Now().Format("20060102T150405"), n, strings.ToLower(method), dumpName(url))
+	err := os.MkdirAll(t.opts.DumpDir, 0700)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(t.opts.DumpDir, name), b.Bytes(), 0600)
+	}
+	if err != nil && t.opts.Output != nil {
+		t.mu.Lock()
+		fmt.Fprintf(t.opts.Output, "! could not dump response: %v\n", err)
+		t.mu.Unlock()
+	}
+}
+
+// requestLine returns the method, full URL and headers actually sent for req,
+// falling back to what was set on req if it never made it onto the wire
+func requestLine(req *resty.Request) (method, url string, header http.Header) {
+	if raw := req.RawRequest; raw != nil {
+		return raw.Method, raw.URL.String(), raw.Header
+	}
+	return req.Method, req.URL, req.Header
+}
+
+// writeHeaders writes header sorted by name, redacting secrets
+func writeHeaders(w io.Writer, prefix string, header http.Header) {
+	header = RedactHeaders(header)
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range header[name] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, v)
+		}
+	}
+}
+
+func writeBody(w io.Writer, prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+// requestBody renders a request body set with resty's SetBody
+func requestBody(body interface{}) []byte {
+	switch b := body.(type) {
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return []byte(fmt.Sprintf("%v", body))
+	}
+	return data
+}
+
+// RedactHeaders returns a copy of header with the values of the API key and
+// other credentials replaced, so it can be logged safely
+func RedactHeaders(header http.Header) http.Header {
+	out := header.Clone()
+	for _, name := range secretHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+var unsafeDumpChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// dumpName turns the path of url into a short file name component
+func dumpName(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if i := strings.Index(url, "/"); i >= 0 {
+		url = url[i+1:]
+	}
+	name := strings.Trim(unsafeDumpChars.ReplaceAllString(url, "_"), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	if name == "" {
+		name = "root"
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+package rapiddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"X-Api-Key":           {"secret"},
+		"Authorization":       {"Bearer secret"},
+		"Proxy-Authorization": {"Basic secret"},
+		"Cookie":              {"session=secret"},
+		"Set-Cookie":          {"session=secret", "other=secret"},
+		"Content-Type":        {"application/json"},
+	}
+	got := RedactHeaders(header)
+	for name, values := range got {
+		want := redacted
+		if name == "Content-Type" {
+			want = "application/json"
+		}
+		if len(values) != 1 || values[0] != want {
+			t.Errorf("%s = %q, want %q", name, values, want)
+		}
+	}
+	if header.Get("X-Api-Key") != "secret" {
+		t.Error("RedactHeaders modified its argument")
+	}
+}
+
+// TestTraceRedactsCredentials runs a traced search through a proxy that needs
+// credentials and echoes them back, and checks that no secret reaches the
+// trace or the dump files
+func TestTraceRedactsCredentials(t *testing.T) {
+	const (
+		apiKey   = "api-key-4f9d2c"
+		password = "proxy-pass-8e1b7a"
+	)
+	var attempts atomic.Int32
+	// The server acts as the proxy, the API is reached through it
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// Fail the first attempt so the error path is traced too
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Header().Set("X-Api-Key", r.Header.Get("X-Api-Key"))
+		w.Header().Set("Authorization", r.Header.Get("Proxy-Authorization"))
+		w.Header().Add("Set-Cookie", "session="+apiKey)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": 200, "msg": "ok", "data": {"total": 1, "status": "ok", "data": [{"subdomain": "www.example.com", "type": "A", "value": "93.184.216.34"}]}}`))
+	}))
+	t.Cleanup(proxy.Close)
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL.User = url.UserPassword("user", password)
+
+	var trace bytes.Buffer
+	dumpDir := t.TempDir()
+	c := NewClient(
+		WithBaseURL("http://rapiddns.test"),
+		WithAPIKey(apiKey),
+		WithProxy(proxyURL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithTrace(TraceOptions{Output: &trace, Bodies: true, DumpDir: dumpDir}),
+	)
+	if _, _, err := c.Search(context.Background(), "example.com", 1, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("proxy got %d attempts, want 2", n)
+	}
+
+	outputs := map[string]string{"trace": trace.String()}
+	dumps, err := filepath.Glob(filepath.Join(dumpDir, "*.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != 1 {
+		t.Fatalf("dump files = %v, want 1", dumps)
+	}
+	for _, path := range dumps {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[filepath.Base(path)] = string(data)
+	}
+
+	for name, out := range outputs {
+		// The proxy credentials travel base64 encoded
+		for _, secret := range []string{apiKey, password, base64.StdEncoding.EncodeToString([]byte("user:" + password))} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s contains %q:\n%s", name, secret, out)
+			}
+		}
+		if !strings.Contains(out, "Set-Cookie: "+redacted) || !strings.Contains(out, "Authorization: "+redacted) {
+			t.Errorf("%s does not show the redacted headers:\n%s", name, out)
+		}
+	}
+	if !strings.Contains(trace.String(), "> X-Api-Key: "+redacted) {
+		t.Errorf("trace does not show the redacted request key:\n%s", trace.String())
+	}
+}